@@ -0,0 +1,213 @@
+package gorouter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AccessLogFormat selects the output of AccessLog
+type AccessLogFormat int
+
+const (
+	// CommonLogFormat writes the Common Log Format followed by the quoted route pattern and the latency
+	CommonLogFormat AccessLogFormat = iota
+	// CombinedLogFormat adds the referer and user agent to CommonLogFormat
+	CombinedLogFormat
+	// JSONLogFormat writes one JSON object per request
+	JSONLogFormat
+	// SlogFormat logs the request to AccessLogConfig.Logger
+	SlogFormat
+)
+
+// AccessLogConfig configures AccessLog
+type AccessLogConfig struct {
+	// Format is the log format, defaults to CommonLogFormat
+	Format AccessLogFormat
+	// Output receives the text and JSON formats, defaults to os.Stdout
+	Output io.Writer
+	// Logger receives SlogFormat records, defaults to slog.Default()
+	Logger *slog.Logger
+	// SkipPaths lists route patterns or request paths that aren't logged, e.g. `/health`
+	SkipPaths []string
+	// Skip reports whether a served request isn't logged, e.g. by status
+	Skip func(w *ResponseWriter, r *http.Request) bool
+}
+
+// accessEntry records one served request
+type accessEntry struct {
+	Time      time.Time
+	Remote    string
+	User      string
+	Method    string
+	URI       string
+	Proto     string
+	Route     string
+	Name      string
+	RequestID string
+	Status    int
+	Bytes     int64
+	Latency   time.Duration
+	Referer   string
+	UserAgent string
+}
+
+// AccessLog returns a middleware logging every request with the matched route
+// pattern, e.g. `/users/:id`, instead of the raw path so logs group by route.
+// Wrap the router to log unmatched requests too:
+//
+//	http.ListenAndServe(":8181", gorouter.AccessLog()(router.ServeHTTP))
+//
+// 访问日志, 记录匹配的路由模式, 状态码, 耗时与字节数
+func AccessLog(config ...AccessLogConfig) MiddlewareType {
+	var c AccessLogConfig
+	if len(config) > 0 {
+		c = config[0]
+	}
+	if c.Output == nil {
+		c.Output = os.Stdout
+	}
+	skipPaths := make(map[string]struct{}, len(c.SkipPaths))
+	for _, path := range c.SkipPaths {
+		skipPaths[path] = struct{}{}
+	}
+	var mu sync.Mutex
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := NewResponseWriter(w)
+			next(rw, r)
+
+			entry := newAccessEntry(rw, r, start)
+			if _, ok := skipPaths[entry.Route]; ok {
+				return
+			}
+			if _, ok := skipPaths[r.URL.Path]; ok {
+				return
+			}
+			if c.Skip != nil && c.Skip(rw, r) {
+				return
+			}
+
+			if c.Format == SlogFormat {
+				entry.log(r, c.Logger)
+				return
+			}
+			line := entry.format(c.Format)
+			mu.Lock()
+			c.Output.Write(line)
+			mu.Unlock()
+		}
+	}
+}
+
+// newAccessEntry records the request served through rw
+func newAccessEntry(rw *ResponseWriter, r *http.Request, start time.Time) *accessEntry {
+	entry := &accessEntry{
+		Time:      start,
+		Remote:    r.RemoteAddr,
+		Method:    r.Method,
+		URI:       r.RequestURI,
+		Proto:     r.Proto,
+		Status:    rw.Status(),
+		Bytes:     rw.Size(),
+		Latency:   time.Since(start),
+		Referer:   r.Referer(),
+		UserAgent: r.UserAgent(),
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		entry.Remote = host
+	}
+	if user, _, ok := r.BasicAuth(); ok {
+		entry.User = user
+	}
+	entry.RequestID = requestID(rw, r)
+	if entry.URI == "" {
+		entry.URI = r.URL.RequestURI()
+	}
+	if entry.Status == 0 {
+		entry.Status = http.StatusOK
+	}
+	if node := matchedNode(rw); node != nil {
+		entry.Route = "/" + trimPathPrefix(node.path)
+		entry.Name = node.name
+	}
+	return entry
+}
+
+// format returns the entry as a log line in the text or JSON format
+func (e *accessEntry) format(format AccessLogFormat) []byte {
+	if format == JSONLogFormat {
+		data, _ := json.Marshal(struct {
+			Time      string  `json:"time"`
+			Remote    string  `json:"remote_addr"`
+			User      string  `json:"user,omitempty"`
+			Method    string  `json:"method"`
+			URI       string  `json:"uri"`
+			Proto     string  `json:"proto"`
+			Route     string  `json:"route,omitempty"`
+			Name      string  `json:"name,omitempty"`
+			RequestID string  `json:"request_id,omitempty"`
+			Status    int     `json:"status"`
+			Bytes     int64   `json:"bytes"`
+			Latency   float64 `json:"latency_ms"`
+			Referer   string  `json:"referer,omitempty"`
+			UserAgent string  `json:"user_agent,omitempty"`
+		}{
+			e.Time.Format(time.RFC3339Nano), e.Remote, e.User, e.Method, e.URI, e.Proto, e.Route, e.Name, e.RequestID,
+			e.Status, e.Bytes, float64(e.Latency) / float64(time.Millisecond), e.Referer, e.UserAgent,
+		})
+		return append(data, '\n')
+	}
+
+	bytes := "-"
+	if e.Bytes > 0 {
+		bytes = strconv.FormatInt(e.Bytes, 10)
+	}
+	line := fmt.Sprintf("%s - %s [%s] %s %d %s",
+		dash(e.Remote), dash(e.User), e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		strconv.Quote(e.Method+" "+e.URI+" "+e.Proto), e.Status, bytes)
+	if format == CombinedLogFormat {
+		line += " " + strconv.Quote(e.Referer) + " " + strconv.Quote(e.UserAgent)
+	}
+	return []byte(fmt.Sprintf("%s %s %.3f\n", line, strconv.Quote(dash(e.Route)), float64(e.Latency)/float64(time.Millisecond)))
+}
+
+// log writes the entry to logger
+func (e *accessEntry) log(r *http.Request, logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	level := slog.LevelInfo
+	if e.Status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	logger.LogAttrs(r.Context(), level, "gorouter: request",
+		slog.String("method", e.Method),
+		slog.String("uri", e.URI),
+		slog.String("route", e.Route),
+		slog.String("name", e.Name),
+		slog.Int("status", e.Status),
+		slog.Int64("bytes", e.Bytes),
+		slog.Duration("latency", e.Latency),
+		slog.String("remote_addr", e.Remote),
+		slog.String("user_agent", e.UserAgent),
+		slog.String("request_id", e.RequestID),
+	)
+}
+
+// dash returns `-` for an empty log field
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
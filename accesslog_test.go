@@ -0,0 +1,129 @@
+package gorouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Test AccessLog records the matched pattern in the text formats
+func TestAccessLog_Formats(t *testing.T) {
+	tests := []struct {
+		format AccessLogFormat
+		want   string
+	}{
+		{CommonLogFormat, `^192\.0\.2\.1 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /users/7\?v=1 HTTP/1\.1" 200 12 "/users/:id" \d+\.\d{3}\n$`},
+		{CombinedLogFormat, `^192\.0\.2\.1 - - \[.+\] "GET /users/7\?v=1 HTTP/1\.1" 200 12 "http://example\.com" "gorouter-test" "/users/:id" \d+\.\d{3}\n$`},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		router := New()
+		router.Use(AccessLog(AccessLogConfig{Format: test.format, Output: &out}))
+		router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(expected))
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/users/7?v=1", nil)
+		req.Header.Set("Referer", "http://example.com")
+		req.Header.Set("User-Agent", "gorouter-test")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		if !regexp.MustCompile(test.want).MatchString(out.String()) {
+			t.Errorf("format %v wrote %q", test.format, out.String())
+		}
+	}
+}
+
+// Test AccessLog JSON output wrapping the router and skip rules
+func TestAccessLog_JSON(t *testing.T) {
+	var out bytes.Buffer
+	router := New()
+	router.GETAndName("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(expected))
+	}, "user")
+	router.GET("/health", func(w http.ResponseWriter, r *http.Request) {})
+	handler := AccessLog(AccessLogConfig{
+		Format:    JSONLogFormat,
+		Output:    &out,
+		SkipPaths: []string{"/health"},
+		Skip: func(w *ResponseWriter, r *http.Request) bool {
+			return r.URL.Query().Get("skip") != ""
+		},
+	})(router.ServeHTTP)
+
+	for _, url := range []string{"/users/7", "/health", "/users/8?skip=1", "/missing"} {
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, url, nil))
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("AccessLog wrote %d lines: %q", len(lines), out.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["route"] != "/users/:id" || entry["name"] != "user" || entry["uri"] != "/users/7" ||
+		entry["status"] != float64(http.StatusCreated) || entry["bytes"] != float64(len(expected)) {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	entry = nil
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := entry["route"]; ok || entry["status"] != float64(http.StatusNotFound) {
+		t.Errorf("unexpected not found entry: %v", entry)
+	}
+}
+
+// Test AccessLog slog output
+func TestAccessLog_Slog(t *testing.T) {
+	var out bytes.Buffer
+	router := New()
+	router.Use(AccessLog(AccessLogConfig{Format: SlogFormat, Logger: slog.New(slog.NewJSONHandler(&out, nil))}))
+	router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/7", nil))
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["level"] != "ERROR" || record["route"] != "/users/:id" || record["status"] != float64(http.StatusInternalServerError) {
+		t.Errorf("unexpected record: %v", record)
+	}
+}
+
+// Test AccessLog keeps the writers of outer middleware such as Compress
+func TestAccessLog_Compress(t *testing.T) {
+	var access bytes.Buffer
+	router := New()
+	router.Use(AccessLog(AccessLogConfig{Format: JSONLogFormat, Output: &access}), Compress())
+	body := strings.Repeat("gorouter ", 512)
+	router.GET("/text/:name", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(body))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/text/a", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Header().Get("Content-Encoding") != "gzip" || rr.Body.Len() >= len(body) {
+		t.Errorf("response isn't compressed: %v %v bytes", rr.Header(), rr.Body.Len())
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(access.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["route"] != "/text/:name" || entry["status"] != float64(http.StatusOK) {
+		t.Errorf("unexpected entry %v", entry)
+	}
+}
@@ -0,0 +1,211 @@
+package gorouter
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrInsufficientScope is returned by bearer validators for valid tokens
+// lacking the permission of the route, the request is answered with 403
+var ErrInsufficientScope = errors.New("insufficient scope")
+
+type (
+	// BasicAuthConfig configures BasicAuth
+	BasicAuthConfig struct {
+		// Realm is the protection space of the challenge, defaults to `Restricted`
+		Realm string
+		// Users maps user names to passwords, compared in constant time
+		Users map[string]string
+		// Validate checks credentials not found in Users, e.g. against hashed passwords
+		Validate func(user string, password string) bool
+	}
+
+	// BearerAuthConfig configures BearerAuth
+	BearerAuthConfig struct {
+		// Realm is the protection space of the challenge, omitted when empty
+		Realm string
+		// Validate returns the principal of a token, ErrInsufficientScope answers
+		// 403 and any other error 401 with the invalid_token error code
+		Validate func(r *http.Request, token string) (interface{}, error)
+	}
+
+	// APIKeyConfig configures APIKeyAuth
+	APIKeyConfig struct {
+		// Header carries the key, defaults to `X-API-Key` when Query is empty too
+		Header string
+		// Query is the query string parameter carrying the key when the header is missing
+		Query string
+		// Realm is the protection space of the challenge, omitted when empty
+		Realm string
+		// Validate returns the principal of a key, it is required
+		Validate func(r *http.Request, key string) (interface{}, error)
+	}
+)
+
+// principalKeyType is the private context key type of the principal
+type principalKeyType struct{}
+
+// principalKey is the context key the auth middlewares store the principal under
+var principalKey = principalKeyType{}
+
+// GetPrincipal returns the principal authenticated by BasicAuth, which is the
+// user name, BearerAuth or APIKeyAuth, nil for unauthenticated requests
+func GetPrincipal(r *http.Request) interface{} {
+	return r.Context().Value(principalKey)
+}
+
+// BasicAuth returns a middleware requiring HTTP Basic credentials,
+// unauthenticated requests get 401 with a Basic challenge. Like the other auth
+// middlewares it lets the CORS preflights answered by the router through and
+// writes rejections with the router's HandleError, as problem+json when
+// ProblemDetails is set.
+// 基本认证
+func BasicAuth(config BasicAuthConfig) MiddlewareType {
+	if config.Realm == "" {
+		config.Realm = "Restricted"
+	}
+	challenge := `Basic realm=` + quoteParam(config.Realm) + `, charset="UTF-8"`
+	passwords := make(map[string][32]byte, len(config.Users))
+	for user, password := range config.Users {
+		passwords[user] = sha256.Sum256([]byte(password))
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if isPreflight(r) {
+				next(w, r)
+				return
+			}
+			user, password, ok := r.BasicAuth()
+			if ok {
+				// 比较摘要, 用户不存在时也执行比较以免泄露时间差
+				expected, found := passwords[user]
+				given := sha256.Sum256([]byte(password))
+				ok = subtle.ConstantTimeCompare(given[:], expected[:]) == 1 && found
+				if !ok && !found && config.Validate != nil {
+					ok = config.Validate(user, password)
+				}
+			}
+			if !ok {
+				unauthorized(w, r, challenge, http.StatusUnauthorized, "")
+				return
+			}
+			next(w, withPrincipal(r, user))
+		}
+	}
+}
+
+// BearerAuth returns a middleware requiring an `Authorization: Bearer` token
+// and answering with RFC 6750 challenges, it panics without Validate
+// Bearer 令牌认证
+func BearerAuth(config BearerAuthConfig) MiddlewareType {
+	if config.Validate == nil {
+		panic("gorouter: nil bearer token validator")
+	}
+	challenge := "Bearer"
+	if config.Realm != "" {
+		challenge += " realm=" + quoteParam(config.Realm)
+	}
+	withError := func(code string) string {
+		if config.Realm != "" {
+			return challenge + `, error="` + code + `"`
+		}
+		return challenge + ` error="` + code + `"`
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if isPreflight(r) {
+				next(w, r)
+				return
+			}
+			parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+			if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || strings.TrimSpace(parts[1]) == "" {
+				unauthorized(w, r, challenge, http.StatusUnauthorized, "")
+				return
+			}
+
+			principal, err := config.Validate(r, strings.TrimSpace(parts[1]))
+			if errors.Is(err, ErrInsufficientScope) {
+				unauthorized(w, r, withError("insufficient_scope"), http.StatusForbidden, "insufficient_scope")
+				return
+			}
+			if err != nil {
+				unauthorized(w, r, withError("invalid_token"), http.StatusUnauthorized, "invalid_token")
+				return
+			}
+			next(w, withPrincipal(r, principal))
+		}
+	}
+}
+
+// APIKeyAuth returns a middleware requiring an API key in a header or the
+// query string, unauthenticated requests get 401 with an APIKey challenge.
+// It panics without Validate.
+// API key 认证
+func APIKeyAuth(config APIKeyConfig) MiddlewareType {
+	if config.Validate == nil {
+		panic("gorouter: nil API key validator")
+	}
+	if config.Header == "" && config.Query == "" {
+		config.Header = "X-API-Key"
+	}
+	var params []string
+	if config.Realm != "" {
+		params = append(params, "realm="+quoteParam(config.Realm))
+	}
+	if config.Header != "" {
+		params = append(params, "header="+quoteParam(config.Header))
+	}
+	if config.Query != "" {
+		params = append(params, "query="+quoteParam(config.Query))
+	}
+	challenge := "APIKey " + strings.Join(params, ", ")
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if isPreflight(r) {
+				next(w, r)
+				return
+			}
+			var key string
+			if config.Header != "" {
+				key = r.Header.Get(config.Header)
+			}
+			if key == "" && config.Query != "" {
+				key = r.URL.Query().Get(config.Query)
+			}
+			if key == "" {
+				unauthorized(w, r, challenge, http.StatusUnauthorized, "")
+				return
+			}
+
+			principal, err := config.Validate(r, key)
+			if err != nil {
+				unauthorized(w, r, challenge, http.StatusUnauthorized, "")
+				return
+			}
+			next(w, withPrincipal(r, principal))
+		}
+	}
+}
+
+// withPrincipal returns r with the principal stored in its context
+func withPrincipal(r *http.Request, principal interface{}) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), principalKey, principal))
+}
+
+// unauthorized writes the challenge and the status with the error code
+func unauthorized(w http.ResponseWriter, r *http.Request, challenge string, status int, code string) {
+	w.Header().Set("WWW-Authenticate", challenge)
+	handleError(w, r, &HTTPError{Status: status, Code: code})
+}
+
+// quoteParam quotes an auth parameter value
+func quoteParam(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,180 @@
+package gorouter
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// authRouter returns a router serving the principal at `/` behind middleware
+func authRouter(middleware MiddlewareType) *Router {
+	router := New()
+	router.Use(middleware)
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(GetPrincipal(r).(string)))
+	})
+	return router
+}
+
+// Test BasicAuth credentials and challenge
+func TestBasicAuth(t *testing.T) {
+	router := authRouter(BasicAuth(BasicAuthConfig{
+		Realm: `Admin "area"`,
+		Users: map[string]string{"jerry": "secret"},
+		Validate: func(user string, password string) bool {
+			return user == "tom" && password == "cat"
+		},
+	}))
+
+	tests := []struct {
+		user, password string
+		code           int
+	}{
+		{"jerry", "secret", http.StatusOK},
+		{"tom", "cat", http.StatusOK},
+		{"jerry", "wrong", http.StatusUnauthorized},
+		{"jerry", "cat", http.StatusUnauthorized},
+		{"", "", http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.user != "" {
+			req.SetBasicAuth(test.user, test.password)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != test.code {
+			t.Errorf("%v:%v returned %v want %v", test.user, test.password, rr.Code, test.code)
+		}
+		if test.code == http.StatusOK && rr.Body.String() != test.user {
+			t.Errorf(errorFormat, rr.Body.String(), test.user)
+		}
+		if test.code == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") != `Basic realm="Admin \"area\"", charset="UTF-8"` {
+			t.Errorf("unexpected challenge %v", rr.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+// Test BearerAuth validator errors and challenges
+func TestBearerAuth(t *testing.T) {
+	router := authRouter(BearerAuth(BearerAuthConfig{
+		Realm: "api",
+		Validate: func(r *http.Request, token string) (interface{}, error) {
+			switch token {
+			case "good":
+				return "user-1", nil
+			case "readonly":
+				return nil, ErrInsufficientScope
+			}
+			return nil, errors.New("unknown token")
+		},
+	}))
+
+	tests := []struct {
+		authorization string
+		code          int
+		challenge     string
+	}{
+		{"Bearer good", http.StatusOK, ""},
+		{"bearer good", http.StatusOK, ""},
+		{"", http.StatusUnauthorized, `Bearer realm="api"`},
+		{"Basic Zm9vOmJhcg==", http.StatusUnauthorized, `Bearer realm="api"`},
+		{"Bearer bad", http.StatusUnauthorized, `Bearer realm="api", error="invalid_token"`},
+		{"Bearer readonly", http.StatusForbidden, `Bearer realm="api", error="insufficient_scope"`},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.authorization != "" {
+			req.Header.Set("Authorization", test.authorization)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != test.code || rr.Header().Get("WWW-Authenticate") != test.challenge {
+			t.Errorf("%q returned %v %q", test.authorization, rr.Code, rr.Header().Get("WWW-Authenticate"))
+		}
+		if test.code == http.StatusOK && rr.Body.String() != "user-1" {
+			t.Errorf(errorFormat, rr.Body.String(), "user-1")
+		}
+	}
+}
+
+// Test APIKeyAuth reads the key from the header or the query string
+func TestAPIKeyAuth(t *testing.T) {
+	router := authRouter(APIKeyAuth(APIKeyConfig{
+		Header: "X-API-Key",
+		Query:  "api_key",
+		Validate: func(r *http.Request, key string) (interface{}, error) {
+			if key == "k1" {
+				return "service-1", nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}))
+
+	tests := []struct {
+		header, url string
+		code        int
+	}{
+		{"k1", "/", http.StatusOK},
+		{"", "/?api_key=k1", http.StatusOK},
+		{"bad", "/", http.StatusUnauthorized},
+		{"", "/", http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.url, nil)
+		if test.header != "" {
+			req.Header.Set("X-API-Key", test.header)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != test.code {
+			t.Errorf("%q %v returned %v want %v", test.header, test.url, rr.Code, test.code)
+		}
+		if test.code == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") != `APIKey header="X-API-Key", query="api_key"` {
+			t.Errorf("unexpected challenge %v", rr.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+// Test auth rejections honor ProblemDetails and nil validators panic
+func TestAuth_ProblemDetails(t *testing.T) {
+	router := authRouter(BearerAuth(BearerAuthConfig{
+		Validate: func(r *http.Request, token string) (interface{}, error) {
+			return nil, ErrInsufficientScope
+		},
+	}))
+	router.ProblemDetails = true
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer readonly")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var problem Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusForbidden || rr.Header().Get("Content-Type") != ProblemContentType ||
+		problem.Status != http.StatusForbidden || problem.Extensions["code"] != "insufficient_scope" {
+		t.Errorf("rejection returned %v %v %+v", rr.Code, rr.Header(), problem)
+	}
+	if rr.Header().Get("WWW-Authenticate") != `Bearer error="insufficient_scope"` {
+		t.Errorf("unexpected challenge %v", rr.Header().Get("WWW-Authenticate"))
+	}
+
+	for name, construct := range map[string]func(){
+		"BearerAuth": func() { BearerAuth(BearerAuthConfig{}) },
+		"APIKeyAuth": func() { APIKeyAuth(APIKeyConfig{}) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%v accepted a nil Validate", name)
+				}
+			}()
+			construct()
+		}()
+	}
+}
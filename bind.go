@@ -0,0 +1,394 @@
+package gorouter
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxMultipartMemory is the memory used by Bind to parse multipart bodies, the rest is stored on disk
+var MaxMultipartMemory int64 = 32 << 20
+
+type (
+	// FieldError describes a field that failed binding or validation
+	FieldError struct {
+		// Field is the name of the field as sent by the client, nested fields are dotted
+		Field string `json:"field"`
+		// Rule is the failed validation rule, `type` when the value could not be converted
+		Rule string `json:"rule"`
+		// Param is the parameter of the rule, e.g. 1 for min=1
+		Param string `json:"param,omitempty"`
+		// Message is a human readable description
+		Message string `json:"message"`
+	}
+
+	// ValidationErrors lists the fields that failed binding or validation,
+	// the default ErrorHandler answers it with 422 Unprocessable Entity
+	ValidationErrors []FieldError
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+	emailPattern    = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// Error implements the error interface
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Bind fills the struct pointed by `v` from the request and validates it:
+//   - the body is decoded according to Content-Type: JSON, XML, url encoded
+//     forms and multipart forms (fields tagged `form:"name"`)
+//   - fields tagged `path:"id"` are set from the route params
+//   - fields tagged `query:"page"` are set from the query string
+//   - fields tagged `validate:"required,min=1"` are validated, see Validate
+//
+// Invalid values and failed rules are returned as ValidationErrors.
+// 绑定请求参数到结构体并校验
+func Bind(r *http.Request, v interface{}) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("gorouter: Bind needs a pointer to a struct, got %T", v)
+	}
+
+	if err := bindBody(r, v); err != nil {
+		return err
+	}
+
+	var errs ValidationErrors
+	params := GetAllParams(r)
+	query := r.URL.Query()
+	errs = append(errs, bindValues(value.Elem(), "path", func(key string) ([]string, bool) {
+		param, ok := params[key]
+		return []string{param}, ok
+	})...)
+	errs = append(errs, bindValues(value.Elem(), "query", func(key string) ([]string, bool) {
+		values, ok := query[key]
+		return values, ok
+	})...)
+	if len(errs) > 0 {
+		return errs
+	}
+	return Validate(v)
+}
+
+// bindBody decodes the request body into v according to its Content-Type
+func bindBody(r *http.Request, v interface{}) error {
+	contentType := r.Header.Get("Content-Type")
+	if r.Body == nil || r.Body == http.NoBody || contentType == "" && r.ContentLength <= 0 {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return &HTTPError{Status: http.StatusUnsupportedMediaType, Message: "invalid content type", Err: err}
+	}
+
+	switch mediaType {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+			return &HTTPError{Status: http.StatusBadRequest, Message: "invalid JSON body", Err: err}
+		}
+	case "application/xml", "text/xml":
+		if err := xml.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+			return &HTTPError{Status: http.StatusBadRequest, Message: "invalid XML body", Err: err}
+		}
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return &HTTPError{Status: http.StatusBadRequest, Message: "invalid form body", Err: err}
+		}
+		if errs := bindForm(r, v); len(errs) > 0 {
+			return errs
+		}
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(MaxMultipartMemory); err != nil {
+			return &HTTPError{Status: http.StatusBadRequest, Message: "invalid multipart body", Err: err}
+		}
+		if errs := bindForm(r, v); len(errs) > 0 {
+			return errs
+		}
+	default:
+		return &HTTPError{Status: http.StatusUnsupportedMediaType, Message: "unsupported content type " + mediaType}
+	}
+	return nil
+}
+
+// bindForm sets the fields tagged `form` from the parsed post form and files
+func bindForm(r *http.Request, v interface{}) ValidationErrors {
+	struc := reflect.ValueOf(v).Elem()
+	if r.MultipartForm != nil {
+		files := r.MultipartForm.File
+		forEachField(struc, func(field reflect.StructField, value reflect.Value) {
+			key := tagName(field, "form")
+			if key == "" || len(files[key]) == 0 {
+				return
+			}
+			switch field.Type {
+			case fileHeaderType:
+				value.Set(reflect.ValueOf(files[key][0]))
+			case fileHeadersType:
+				value.Set(reflect.ValueOf(files[key]))
+			}
+		})
+	}
+
+	return bindValues(struc, "form", func(key string) ([]string, bool) {
+		values, ok := r.PostForm[key]
+		return values, ok
+	})
+}
+
+// bindValues sets the fields of struc tagged with `tag` from the values returned by lookup
+func bindValues(struc reflect.Value, tag string, lookup func(key string) ([]string, bool)) ValidationErrors {
+	var errs ValidationErrors
+	forEachField(struc, func(field reflect.StructField, value reflect.Value) {
+		key := tagName(field, tag)
+		if key == "" || field.Type == fileHeaderType || field.Type == fileHeadersType {
+			return
+		}
+		values, ok := lookup(key)
+		if !ok || len(values) == 0 {
+			return
+		}
+		if err := setValue(value, values); err != nil {
+			errs = append(errs, FieldError{
+				Field:   key,
+				Rule:    "type",
+				Param:   value.Type().String(),
+				Message: fmt.Sprintf("%s must be a valid %s", key, value.Type()),
+			})
+		}
+	})
+	return errs
+}
+
+// forEachField calls fn for every settable field of struc, embedded structs included
+func forEachField(struc reflect.Value, fn func(field reflect.StructField, value reflect.Value)) {
+	t := struc.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := struc.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			forEachField(value, fn)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		fn(field, value)
+	}
+}
+
+// tagName returns the name of the field in the given tag, empty when absent or `-`
+func tagName(field reflect.StructField, tag string) string {
+	name := strings.Split(field.Tag.Get(tag), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// setValue converts values to the type of value, slices receive every value
+func setValue(value reflect.Value, values []string) error {
+	switch value.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(value.Type().Elem())
+		if err := setValue(elem.Elem(), values); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	case reflect.Slice:
+		slice := reflect.MakeSlice(value.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := setValue(slice.Index(i), []string{v}); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+		return nil
+	}
+
+	raw := values[0]
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(n)
+	default:
+		return fmt.Errorf("gorouter: unsupported field type %s", value.Type())
+	}
+	return nil
+}
+
+// Validate checks the `validate` tags of the struct pointed by v, nested
+// structs included, and returns ValidationErrors listing every failed rule.
+// Supported rules are required, min=N, max=N, len=N (values for numbers,
+// lengths for strings, slices and maps), oneof=a b c and email.
+func Validate(v interface{}) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if errs := validateStruct(value, ""); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateStruct validates the fields of struc, `prefix` is prepended to nested field names
+func validateStruct(struc reflect.Value, prefix string) ValidationErrors {
+	var errs ValidationErrors
+	forEachField(struc, func(field reflect.StructField, value reflect.Value) {
+		name := prefix + fieldName(field)
+
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			if rule == "" {
+				continue
+			}
+			param := ""
+			if i := strings.Index(rule, "="); i >= 0 {
+				rule, param = rule[:i], rule[i+1:]
+			}
+			if message, ok := checkRule(value, rule, param); !ok {
+				errs = append(errs, FieldError{Field: name, Rule: rule, Param: param, Message: name + " " + message})
+				// 一个字段只报告第一个失败的规则
+				break
+			}
+		}
+
+		nested := value
+		if nested.Kind() == reflect.Ptr && !nested.IsNil() {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && nested.Type() != timeType {
+			errs = append(errs, validateStruct(nested, name+".")...)
+		}
+	})
+	return errs
+}
+
+// fieldName returns the name clients use for the field
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "path", "query", "xml"} {
+		if name := tagName(field, tag); name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// checkRule checks a single rule and returns the failure message
+func checkRule(value reflect.Value, rule string, param string) (string, bool) {
+	if rule == "required" {
+		return "is required", !value.IsZero()
+	}
+
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "", true
+		}
+		value = value.Elem()
+	}
+
+	switch rule {
+	case "min", "max", "len":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return "has an invalid " + rule + " rule", false
+		}
+		size, isLength := measure(value)
+		unit := ""
+		if isLength {
+			unit = " in length"
+		}
+		switch rule {
+		case "min":
+			return fmt.Sprintf("must be at least %s%s", param, unit), size >= limit
+		case "max":
+			return fmt.Sprintf("must be at most %s%s", param, unit), size <= limit
+		default:
+			return fmt.Sprintf("must be exactly %s%s", param, unit), size == limit
+		}
+	case "oneof":
+		current := fmt.Sprint(value.Interface())
+		for _, option := range strings.Fields(param) {
+			if current == option {
+				return "", true
+			}
+		}
+		return "must be one of " + strings.Join(strings.Fields(param), ", "), false
+	case "email":
+		return "must be a valid email address", value.Kind() == reflect.String && (value.Len() == 0 || emailPattern.MatchString(value.String()))
+	}
+	return "has an unknown rule " + rule, false
+}
+
+// measure returns the number compared by min, max and len and whether it is a length
+func measure(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), false
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), true
+	}
+	return 0, false
+}
+
+// asValidationErrors reports whether err holds ValidationErrors
+func asValidationErrors(err error) (ValidationErrors, bool) {
+	var errs ValidationErrors
+	ok := errors.As(err, &errs)
+	return errs, ok
+}
@@ -0,0 +1,141 @@
+package gorouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type bindAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type bindRequest struct {
+	ID      int          `path:"id" validate:"min=1"`
+	Page    int          `query:"page"`
+	Tags    []string     `query:"tag"`
+	Name    string       `json:"name" form:"name" validate:"required,max=8"`
+	Email   string       `json:"email" form:"email" validate:"email"`
+	Role    string       `json:"role" form:"role" validate:"oneof=admin user"`
+	Address *bindAddress `json:"address"`
+}
+
+func bindRoute(t *testing.T, req *http.Request) (*bindRequest, error) {
+	t.Helper()
+	router := New()
+
+	var (
+		dst bindRequest
+		err error
+	)
+	router.POST("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		err = Bind(r, &dst)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	return &dst, err
+}
+
+// Test Bind JSON body, path and query
+func TestBind_JSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/users/7?page=2&tag=a&tag=b", strings.NewReader(`{"name":"jerry","email":"j@example.com","role":"admin","address":{"city":"Paris"}}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	dst, err := bindRoute(t, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bindRequest{ID: 7, Page: 2, Tags: []string{"a", "b"}, Name: "jerry", Email: "j@example.com", Role: "admin", Address: &bindAddress{City: "Paris"}}
+	if !reflect.DeepEqual(*dst, want) {
+		t.Errorf("Bind returned %+v, want %+v", *dst, want)
+	}
+}
+
+// Test Bind url encoded and multipart forms
+func TestBind_Form(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader("name=jerry&role=user"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if dst, err := bindRoute(t, req); err != nil || dst.Name != "jerry" || dst.Role != "user" {
+		t.Errorf("Bind returned %+v %v", dst, err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("name", "jerry")
+	writer.WriteField("role", "admin")
+	writer.Close()
+	req = httptest.NewRequest(http.MethodPost, "/users/1", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if dst, err := bindRoute(t, req); err != nil || dst.Name != "jerry" || dst.Role != "admin" {
+		t.Errorf("Bind returned %+v %v", dst, err)
+	}
+
+	var upload struct {
+		File *multipart.FileHeader `form:"file"`
+	}
+	body.Reset()
+	writer = multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "a.txt")
+	part.Write([]byte("hello"))
+	writer.Close()
+	req = httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if err := Bind(req, &upload); err != nil || upload.File == nil || upload.File.Filename != "a.txt" {
+		t.Errorf("Bind returned %+v %v", upload.File, err)
+	}
+}
+
+// Test Bind validation errors
+func TestBind_Validation(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/users/0?page=x", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	if _, err := bindRoute(t, req); !reflect.DeepEqual(err, ValidationErrors{
+		{Field: "page", Rule: "type", Param: "int", Message: "page must be a valid int"},
+	}) {
+		t.Errorf("unexpected conversion errors: %#v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/users/0", strings.NewReader(`{"name":"jerrymouse","email":"nope","role":"root","address":{}}`))
+	req.Header.Set("Content-Type", "application/json")
+	_, err := bindRoute(t, req)
+
+	var fields []string
+	errs, _ := err.(ValidationErrors)
+	for _, fieldErr := range errs {
+		fields = append(fields, fieldErr.Field+":"+fieldErr.Rule)
+	}
+	want := []string{"id:min", "name:max", "email:email", "role:oneof", "address.city:required"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("unexpected validation errors: got %v want %v", fields, want)
+	}
+}
+
+// Test validation errors are answered with 422
+func TestBind_ValidationResponse(t *testing.T) {
+	router := New()
+	router.ProblemDetails = true
+	router.POSTE("/users/:id", WithContext(func(c *Context) error {
+		var dst bindRequest
+		return c.Bind(&dst)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader(`{"role":"admin"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var problem struct {
+		Status int          `json:"status"`
+		Errors []FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusUnprocessableEntity || len(problem.Errors) != 1 || problem.Errors[0].Field != "name" {
+		t.Errorf("unexpected response: %v %s", rr.Code, rr.Body.String())
+	}
+}
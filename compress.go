@@ -0,0 +1,334 @@
+package gorouter
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultCompressSkipTypes are the already compressed content types Compress leaves as is
+var DefaultCompressSkipTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif",
+	"video/*", "audio/*", "font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+	"application/x-7z-compressed", "application/x-rar-compressed",
+}
+
+// Encoder compresses responses with a content coding, e.g. zstd:
+//
+//	gorouter.Encoder{Encoding: "zstd", NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+//		return zstd.NewWriter(w)
+//	}}
+type Encoder struct {
+	// Encoding is the content coding token of Accept-Encoding and Content-Encoding
+	Encoding string
+	// NewWriter returns a writer compressing into w, Flush is called on it when it has the method
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+}
+
+// CompressConfig configures Compress
+type CompressConfig struct {
+	// Level is the gzip and deflate compression level, defaults to gzip.DefaultCompression
+	Level int
+	// MinLength is the smallest body compressed in bytes, defaults to 1024
+	MinLength int
+	// SkipContentTypes lists content types not compressed, `type/*` ranges are
+	// allowed, defaults to DefaultCompressSkipTypes
+	SkipContentTypes []string
+	// Encoders lists the encoders by preference, defaults to gzip then deflate
+	Encoders []Encoder
+}
+
+// Compress returns a middleware compressing responses with the best encoding
+// of Accept-Encoding. Bodies smaller than MinLength, already compressed
+// content types and responses with a Content-Encoding are sent as is.
+// Turn it on for a router or group with Use, or for a route with Route.Use.
+// 响应压缩
+func Compress(config ...CompressConfig) MiddlewareType {
+	var c CompressConfig
+	if len(config) > 0 {
+		c = config[0]
+	}
+	if c.Level == 0 {
+		c.Level = gzip.DefaultCompression
+	}
+	if c.MinLength == 0 {
+		c.MinLength = 1024
+	}
+	if c.SkipContentTypes == nil {
+		c.SkipContentTypes = DefaultCompressSkipTypes
+	}
+	if len(c.Encoders) == 0 {
+		c.Encoders = []Encoder{pooledEncoder("gzip", c.Level, func(w io.Writer, level int) (resetWriter, error) {
+			return gzip.NewWriterLevel(w, level)
+		}), pooledEncoder("deflate", c.Level, func(w io.Writer, level int) (resetWriter, error) {
+			return flate.NewWriter(w, level)
+		})}
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoder, ok := negotiateEncoding(r.Header.Get("Accept-Encoding"), c.Encoders)
+			if !ok || r.Method == http.MethodHead {
+				next(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, config: &c, encoder: encoder}
+			defer cw.Close()
+			next(cw, r)
+		}
+	}
+}
+
+// negotiateEncoding returns the encoder with the highest q-value in
+// Accept-Encoding, ties are broken by the order of the encoders
+func negotiateEncoding(header string, encoders []Encoder) (Encoder, bool) {
+	var (
+		best  Encoder
+		bestQ float64
+	)
+	ranges := parseAccept(header)
+	for _, encoder := range encoders {
+		q, specific := 0.0, false
+		for _, accept := range ranges {
+			if accept.mediaType == strings.ToLower(encoder.Encoding) {
+				q, specific = accept.q, true
+			} else if accept.mediaType == "*" && !specific {
+				q = accept.q
+			}
+		}
+		if q > bestQ {
+			best, bestQ = encoder, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// resetWriter is a compressor that can be reused for another response
+type resetWriter interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// pooledEncoder returns an Encoder reusing its compressors through a sync.Pool
+func pooledEncoder(encoding string, level int, newWriter func(w io.Writer, level int) (resetWriter, error)) Encoder {
+	var pool sync.Pool
+	return Encoder{Encoding: encoding, NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+		if writer, ok := pool.Get().(resetWriter); ok {
+			writer.Reset(w)
+			return &pooledWriter{resetWriter: writer, pool: &pool}, nil
+		}
+		writer, err := newWriter(w, level)
+		if err != nil {
+			return nil, err
+		}
+		return &pooledWriter{resetWriter: writer, pool: &pool}, nil
+	}}
+}
+
+// pooledWriter returns its compressor to the pool when closed
+type pooledWriter struct {
+	resetWriter
+	pool *sync.Pool
+}
+
+// Flush flushes the compressor when it can
+func (w *pooledWriter) Flush() error {
+	if flusher, ok := w.resetWriter.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// Close closes the compressor and puts it back into the pool
+func (w *pooledWriter) Close() error {
+	err := w.resetWriter.Close()
+	w.pool.Put(w.resetWriter)
+	return err
+}
+
+// compressWriter buffers the start of the body until it knows whether to compress it
+type compressWriter struct {
+	http.ResponseWriter
+	config  *CompressConfig
+	encoder Encoder
+	// status records the status code until the header is sent
+	status int
+	// buf records the body written before deciding
+	buf []byte
+	// decided is set once the header is sent, writer is nil when not compressing
+	decided bool
+	writer  io.WriteCloser
+}
+
+// WriteHeader records the status, the header is sent once compression is decided
+func (w *compressWriter) WriteHeader(status int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+	// 无响应体或已知长度太小时立即决定
+	if !bodyAllowed(status) || w.tooShort() {
+		w.decide(false)
+	}
+}
+
+// Write buffers the body until MinLength bytes are written
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.config.MinLength {
+			return len(data), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.writer != nil {
+		return w.writer.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush decides with the buffered body and flushes the compressor and the response
+func (w *compressWriter) Flush() {
+	w.FlushError()
+}
+
+// FlushError implements the flusher of http.ResponseController
+func (w *compressWriter) FlushError() error {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		// 流式响应即使较小也压缩
+		if err := w.decide(true); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok {
+		if err := flusher.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack hands the connection over without compression
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.decided = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Close sends what's left of the response and finishes the compressed stream
+func (w *compressWriter) Close() error {
+	if !w.decided {
+		if w.status == 0 && len(w.buf) == 0 {
+			// 处理函数没有写响应, 交给外层的 writer
+			return nil
+		}
+		if err := w.decide(len(w.buf) >= w.config.MinLength); err != nil {
+			return err
+		}
+	}
+	if w.writer != nil {
+		return w.writer.Close()
+	}
+	return nil
+}
+
+// decide sends the header, compressed when `compress` is set and the response
+// allows it, and then the buffered body
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	header := w.Header()
+	if compress && bodyAllowed(w.status) && w.status != http.StatusPartialContent && header.Get("Content-Encoding") == "" {
+		if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+			header.Set("Content-Type", http.DetectContentType(w.buf))
+		}
+		compress = !w.skipped(header.Get("Content-Type"))
+	} else {
+		compress = false
+	}
+
+	if compress {
+		writer, err := w.encoder.NewWriter(w.ResponseWriter)
+		if err != nil {
+			return err
+		}
+		w.writer = writer
+		header.Set("Content-Encoding", w.encoder.Encoding)
+		header.Del("Content-Length")
+		// 压缩后的表示与原始表示不同, 强 ETag 改为弱 ETag
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	var err error
+	if w.writer != nil {
+		_, err = w.writer.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// tooShort reports whether the Content-Length header is below MinLength
+func (w *compressWriter) tooShort() bool {
+	length, err := strconv.Atoi(w.Header().Get("Content-Length"))
+	return err == nil && length < w.config.MinLength
+}
+
+// skipped reports whether the content type is in SkipContentTypes
+func (w *compressWriter) skipped(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, skip := range w.config.SkipContentTypes {
+		if matchMediaRange(strings.ToLower(skip), mediaType) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyAllowed reports whether a response with status may have a body
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
@@ -0,0 +1,127 @@
+package gorouter
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test Compress negotiates Accept-Encoding and skips small or compressed bodies
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(expected, 200)
+	router := New()
+	router.Use(Compress())
+	router.GET("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(large[:1000]))
+		w.Write([]byte(large[1000:]))
+	})
+	router.GET("/small", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(expected))
+	})
+	router.GET("/png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte(large))
+	})
+	router.GET("/encoded", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		w.Write([]byte(large))
+	})
+	router.GET("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		url, acceptEncoding string
+		encoding            string
+		body                string
+	}{
+		{"/large", "gzip, deflate", "gzip", large},
+		{"/large", "gzip;q=0.5, deflate", "deflate", large},
+		{"/large", "*", "gzip", large},
+		{"/large", "gzip;q=0, *", "deflate", large},
+		{"/large", "", "", large},
+		{"/large", "br", "", large},
+		{"/small", "gzip", "", expected},
+		{"/png", "gzip", "", large},
+		{"/encoded", "gzip", "br", large},
+		{"/empty", "gzip", "", ""},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.url, nil)
+		if test.acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", test.acceptEncoding)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Header().Get("Content-Encoding") != test.encoding || rr.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("%v %q returned encoding %q vary %q", test.url, test.acceptEncoding, rr.Header().Get("Content-Encoding"), rr.Header().Get("Vary"))
+			continue
+		}
+		if body := decompress(t, test.encoding, rr.Body); body != test.body {
+			t.Errorf("%v %q returned body of %d bytes want %d", test.url, test.acceptEncoding, len(body), len(test.body))
+		}
+		if test.url == "/large" && test.encoding != "" && rr.Header().Get("ETag") != `W/"v1"` {
+			t.Errorf("compressed response kept ETag %v", rr.Header().Get("ETag"))
+		}
+	}
+}
+
+// Test Compress flushes streamed responses and works per route
+func TestCompress_FlushRoute(t *testing.T) {
+	router := New()
+	router.GET("/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: 1\n\n"))
+		w.(http.Flusher).Flush()
+		w.Write([]byte("data: 2\n\n"))
+	}).Use(Compress())
+	router.GET("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat(expected, 200)))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if !rr.Flushed || rr.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("stream returned flushed %v encoding %q", rr.Flushed, rr.Header().Get("Content-Encoding"))
+	}
+	if body := decompress(t, "gzip", rr.Body); body != "data: 1\n\ndata: 2\n\n" {
+		t.Errorf(errorFormat, body, "data: 1\n\ndata: 2\n\n")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/plain", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Header().Get("Content-Encoding") != "" {
+		t.Errorf("route without Compress returned encoding %q", rr.Header().Get("Content-Encoding"))
+	}
+}
+
+// decompress decodes body with the content coding
+func decompress(t *testing.T, encoding string, body *bytes.Buffer) string {
+	var reader io.Reader = body
+	switch encoding {
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = gz
+	case "deflate":
+		reader = flate.NewReader(body)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,132 @@
+package gorouter
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// Context wraps the http.ResponseWriter and *http.Request of a handler with
+// helpers to read params and write responses. Plain http.HandlerFunc handlers
+// keep working, Context is opt-in through WithContext.
+type Context struct {
+	// Writer is the response writer of the request
+	Writer http.ResponseWriter
+	// Request is the handled request
+	Request *http.Request
+}
+
+// valueKey is the context key of a value stored with SetValue
+type valueKey string
+
+// storedValue wraps a stored value so nil values are found too
+type storedValue struct {
+	value interface{}
+}
+
+// SetValue returns r with `value` stored under `key` in its context, so
+// middleware can pass values on to handlers reading them with GetValue or Context.Get
+func SetValue(r *http.Request, key string, value interface{}) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), valueKey(key), storedValue{value}))
+}
+
+// GetValue returns the value stored with SetValue and whether it exists
+func GetValue(r *http.Request, key string) (interface{}, bool) {
+	stored, ok := r.Context().Value(valueKey(key)).(storedValue)
+	return stored.value, ok
+}
+
+// NewContext returns a Context for the given response writer and request
+func NewContext(w http.ResponseWriter, r *http.Request) *Context {
+	return &Context{Writer: w, Request: r}
+}
+
+// WithContext adapts a Context handler to a HandlerE, register it with
+// GETE, POSTE... so returned errors reach the router's ErrorHandler:
+//
+//	router.GETE("/users/:id", gorouter.WithContext(func(c *gorouter.Context) error {
+//		return c.JSON(http.StatusOK, users[c.Param("id")])
+//	}))
+func WithContext(handle func(c *Context) error) HandlerE {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		return handle(NewContext(w, r))
+	}
+}
+
+// Param returns the route param `key`
+func (c *Context) Param(key string) string {
+	return GetParam(c.Request, key)
+}
+
+// Query returns the first value of the query string parameter `key`
+func (c *Context) Query(key string) string {
+	return c.Request.URL.Query().Get(key)
+}
+
+// Set stores a value in the context of c.Request, see SetValue
+func (c *Context) Set(key string, value interface{}) {
+	c.Request = SetValue(c.Request, key, value)
+}
+
+// Get returns the value stored with Set or by middleware with SetValue and whether it exists
+func (c *Context) Get(key string) (interface{}, bool) {
+	return GetValue(c.Request, key)
+}
+
+// JSON writes `v` encoded as JSON with the given status
+func (c *Context) JSON(status int, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Blob(status, "application/json", data)
+}
+
+// XML writes `v` encoded as XML with the given status
+func (c *Context) XML(status int, v interface{}) error {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Blob(status, "application/xml; charset=utf-8", append([]byte(xml.Header), data...))
+}
+
+// String writes a formatted plain text response with the given status
+func (c *Context) String(status int, format string, args ...interface{}) error {
+	return c.Blob(status, "text/plain; charset=utf-8", []byte(fmt.Sprintf(format, args...)))
+}
+
+// HTML writes an HTML response with the given status
+func (c *Context) HTML(status int, html string) error {
+	return c.Blob(status, "text/html; charset=utf-8", []byte(html))
+}
+
+// Blob writes data with the given status and content type
+func (c *Context) Blob(status int, contentType string, data []byte) error {
+	c.Writer.Header().Set("Content-Type", contentType)
+	c.Writer.WriteHeader(status)
+	_, err := c.Writer.Write(data)
+	return err
+}
+
+// Redirect redirects the request to `url` with a 3xx status
+func (c *Context) Redirect(status int, url string) error {
+	if status < http.StatusMultipleChoices || status > http.StatusPermanentRedirect {
+		return fmt.Errorf("gorouter: invalid redirect status %d", status)
+	}
+	http.Redirect(c.Writer, c.Request, url, status)
+	return nil
+}
+
+// NoContent writes the status without body
+func (c *Context) NoContent(status int) error {
+	c.Writer.WriteHeader(status)
+	return nil
+}
+
+// Bind binds and validates the request into `v`, see Bind
+func (c *Context) Bind(v interface{}) error {
+	return Bind(c.Request, v)
+}
@@ -0,0 +1,131 @@
+package gorouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type contextUser struct {
+	ID   string `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+// Test Context response helpers
+func TestContext_Responses(t *testing.T) {
+	router := New()
+
+	router.GETE("/users/:id", WithContext(func(c *Context) error {
+		return c.JSON(http.StatusOK, contextUser{ID: c.Param("id"), Name: c.Query("name")})
+	}))
+	router.GETE("/xml/:id", WithContext(func(c *Context) error {
+		return c.XML(http.StatusCreated, contextUser{ID: c.Param("id")})
+	}))
+	router.GETE("/text", WithContext(func(c *Context) error {
+		return c.String(http.StatusAccepted, "hi, %s", "gorouter")
+	}))
+	router.GETE("/html", WithContext(func(c *Context) error {
+		return c.HTML(http.StatusOK, "<b>hi</b>")
+	}))
+	router.GETE("/redirect", WithContext(func(c *Context) error {
+		return c.Redirect(http.StatusFound, "/text")
+	}))
+	router.DELETEE("/users/:id", WithContext(func(c *Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		method, url string
+		code        int
+		contentType string
+		body        string
+	}{
+		{http.MethodGet, "/users/7?name=jerry", http.StatusOK, "application/json", `{"id":"7","name":"jerry"}`},
+		{http.MethodGet, "/xml/7", http.StatusCreated, "application/xml; charset=utf-8", `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<contextUser><id>7</id><name></name></contextUser>`},
+		{http.MethodGet, "/text", http.StatusAccepted, "text/plain; charset=utf-8", expected},
+		{http.MethodGet, "/html", http.StatusOK, "text/html; charset=utf-8", "<b>hi</b>"},
+		{http.MethodDelete, "/users/7", http.StatusNoContent, "", ""},
+	}
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(test.method, test.url, nil))
+		if rr.Code != test.code || rr.Header().Get("Content-Type") != test.contentType || rr.Body.String() != test.body {
+			t.Errorf("%v %v returned %v %v %q", test.method, test.url, rr.Code, rr.Header().Get("Content-Type"), rr.Body.String())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/redirect", nil))
+	if rr.Code != http.StatusFound || rr.Header().Get("Location") != "/text" {
+		t.Errorf("unexpected redirect: %v %v", rr.Code, rr.Header().Get("Location"))
+	}
+}
+
+// Test Context.Bind and values
+func TestContext_Bind(t *testing.T) {
+	router := New()
+
+	var bound contextUser
+	router.POSTE("/users", WithContext(func(c *Context) error {
+		c.Set("user", "jerry")
+		if value, ok := c.Get("user"); !ok || value != "jerry" {
+			t.Errorf("Get returned %v %v", value, ok)
+		}
+		if err := c.Bind(&bound); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"id":"1","name":"jerry"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated || bound != (contextUser{ID: "1", Name: "jerry"}) {
+		t.Errorf("unexpected bind: %v %+v", rr.Code, bound)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{`))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`id=1`))
+	req.Header.Set("Content-Type", "text/csv")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusUnsupportedMediaType)
+	}
+
+	if err := NewContext(rr, req).Redirect(http.StatusOK, "/"); err == nil {
+		t.Errorf("Redirect accepted a non redirect status: %v", err)
+	}
+}
+
+// Test middleware values reach Context.Get
+func TestContext_Values(t *testing.T) {
+	router := New()
+	router.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			next(w, SetValue(r, "tenant", "acme"))
+		}
+	})
+	router.GETE("/tenant", WithContext(func(c *Context) error {
+		tenant, ok := c.Get("tenant")
+		if _, found := c.Get("missing"); found || !ok {
+			t.Errorf("Get returned %v %v", tenant, ok)
+		}
+		return c.String(http.StatusOK, "%v", tenant)
+	}))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tenant", nil))
+	if rr.Body.String() != "acme" {
+		t.Errorf(errorFormat, rr.Body.String(), "acme")
+	}
+}
@@ -0,0 +1,128 @@
+package gorouter
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures CORS
+type CORSConfig struct {
+	// AllowOrigins lists the allowed origins: `*` for any origin, an exact
+	// origin such as `https://example.com` or a wildcard subdomain such as
+	// `https://*.example.com`
+	AllowOrigins []string
+	// AllowOriginFunc reports whether an origin is allowed, it is checked after AllowOrigins
+	AllowOriginFunc func(origin string) bool
+	// AllowHeaders lists the request headers allowed in preflights,
+	// the requested headers are allowed when empty
+	AllowHeaders []string
+	// ExposeHeaders lists the response headers readable by the browser
+	ExposeHeaders []string
+	// AllowCredentials allows cookies and authorization headers, the origin
+	// is then echoed instead of `*`
+	AllowCredentials bool
+	// MaxAge is how long browsers cache preflight responses, omitted when zero
+	MaxAge time.Duration
+}
+
+// CORS returns a middleware answering cross-origin requests. Register it with
+// Router.Use: the router answers OPTIONS requests through the middleware of
+// the route of the Access-Control-Request-Method, so preflights list exactly
+// the methods registered for the path. The auth and rate limit middlewares
+// let these preflights through, as browsers send them without credentials,
+// so CORS answers them whatever the order of Use. Register CORS with the last
+// Use call when other middleware may reject preflights.
+// 跨域资源共享, 预检请求根据路由表返回允许的方法
+func CORS(config CORSConfig) MiddlewareType {
+	var anyOrigin bool
+	origins := make(map[string]struct{})
+	var wildcards [][2]string
+	for _, origin := range config.AllowOrigins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == "*":
+			anyOrigin = true
+		case strings.Contains(origin, "://*."):
+			parts := strings.SplitN(origin, "*", 2)
+			wildcards = append(wildcards, [2]string{parts[0], parts[1]})
+		default:
+			origins[origin] = struct{}{}
+		}
+	}
+
+	allowed := func(origin string) bool {
+		if anyOrigin {
+			return true
+		}
+		lower := strings.ToLower(origin)
+		if _, ok := origins[lower]; ok {
+			return true
+		}
+		for _, wildcard := range wildcards {
+			// 通配符至少匹配一级子域名
+			if len(lower) > len(wildcard[0])+len(wildcard[1]) &&
+				strings.HasPrefix(lower, wildcard[0]) && strings.HasSuffix(lower, wildcard[1]) {
+				return true
+			}
+		}
+		return config.AllowOriginFunc != nil && config.AllowOriginFunc(origin)
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			header.Add("Vary", "Origin")
+			origin := r.Header.Get("Origin")
+			if origin == "" || !allowed(origin) {
+				next(w, r)
+				return
+			}
+
+			if anyOrigin && !config.AllowCredentials {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Set("Access-Control-Allow-Origin", origin)
+			}
+			if config.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			requestMethod := r.Header.Get("Access-Control-Request-Method")
+			if r.Method != http.MethodOptions || requestMethod == "" {
+				if len(config.ExposeHeaders) > 0 {
+					header.Set("Access-Control-Expose-Headers", strings.Join(config.ExposeHeaders, ", "))
+				}
+				next(w, r)
+				return
+			}
+
+			// 预检请求
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			methods := AllowedMethods(r)
+			if len(methods) == 0 {
+				next(w, r)
+				return
+			}
+			header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			if len(config.AllowHeaders) > 0 {
+				header.Set("Access-Control-Allow-Headers", strings.Join(config.AllowHeaders, ", "))
+			} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				header.Set("Access-Control-Allow-Headers", requested)
+			}
+			if config.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge/time.Second)))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+// isPreflight reports whether r is a CORS preflight answered by the router
+// itself, there is no OPTIONS route for it to protect
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != "" && len(AllowedMethods(r)) > 0
+}
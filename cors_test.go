@@ -0,0 +1,169 @@
+package gorouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test CORS preflights list the methods of the route table
+func TestCORS_Preflight(t *testing.T) {
+	router := New()
+	router.Use(CORS(CORSConfig{
+		AllowOrigins:     []string{"https://example.com", "https://*.example.org"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}))
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	router.GET("/users/:id", handler)
+	router.PUT("/users/:id", handler)
+	router.DELETE("/users/:id", handler)
+	router.GET("/other", handler)
+
+	tests := []struct {
+		origin      string
+		allowOrigin string
+	}{
+		{"https://example.com", "https://example.com"},
+		{"https://api.example.org", "https://api.example.org"},
+		{"https://example.org", ""},
+		{"https://evil.com", ""},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodOptions, "/users/7", nil)
+		req.Header.Set("Origin", test.origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+		req.Header.Set("Access-Control-Request-Headers", "Content-Type, X-Token")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		header := rr.Header()
+		if rr.Code != http.StatusNoContent || header.Get("Access-Control-Allow-Origin") != test.allowOrigin {
+			t.Errorf("origin %v returned %v %q", test.origin, rr.Code, header.Get("Access-Control-Allow-Origin"))
+		}
+		if test.allowOrigin == "" {
+			if header.Get("Access-Control-Allow-Methods") != "" {
+				t.Errorf("origin %v was allowed methods", test.origin)
+			}
+			continue
+		}
+		if header.Get("Access-Control-Allow-Methods") != "DELETE, GET, PUT" ||
+			header.Get("Access-Control-Allow-Headers") != "Content-Type, X-Token" ||
+			header.Get("Access-Control-Allow-Credentials") != "true" ||
+			header.Get("Access-Control-Max-Age") != "600" ||
+			!strings.Contains(strings.Join(header.Values("Vary"), ","), "Access-Control-Request-Method") {
+			t.Errorf("unexpected preflight headers: %v", header)
+		}
+	}
+
+	// 没有 CORS 头的 OPTIONS 请求也会自动应答
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodOptions, "/other", nil))
+	if rr.Code != http.StatusNoContent || rr.Header().Get("Allow") != "GET, OPTIONS" {
+		t.Errorf("OPTIONS returned %v %v", rr.Code, rr.Header().Get("Allow"))
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodOptions, "/missing", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("OPTIONS on a missing path returned %v", rr.Code)
+	}
+}
+
+// Test preflights run the middleware of the requested method and get past
+// auth and rate limit middleware registered after CORS
+func TestCORS_PreflightMiddleware(t *testing.T) {
+	router := New()
+	router.Use(CORS(CORSConfig{AllowOrigins: []string{"*"}}))
+	router.Use(
+		BearerAuth(BearerAuthConfig{Validate: func(r *http.Request, token string) (interface{}, error) {
+			return token, nil
+		}}),
+		RateLimit(RateLimitConfig{Limit: 1}),
+	)
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	router.GET("/users/:id", handler)
+	router.PUT("/users/:id", handler)
+
+	// 只有 PUT 路由配置了 CORS
+	routes := New()
+	routes.GET("/items", handler)
+	routes.PUT("/items", handler).Use(CORS(CORSConfig{AllowOrigins: []string{"*"}, MaxAge: time.Minute}))
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodOptions, "/users/7", nil)
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusNoContent || rr.Header().Get("Access-Control-Allow-Methods") != "GET, PUT" {
+			t.Errorf("preflight returned %v %v", rr.Code, rr.Header())
+		}
+
+		req = httptest.NewRequest(http.MethodOptions, "/items", nil)
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+		rr = httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		if rr.Header().Get("Access-Control-Max-Age") != "60" {
+			t.Errorf("preflight didn't run the middleware of the PUT route: %v", rr.Header())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/users/7", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated request returned %v", rr.Code)
+	}
+
+	// 没有 Origin 的 OPTIONS 请求不是预检请求
+	req := httptest.NewRequest(http.MethodOptions, "/users/7", nil)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("OPTIONS without Origin returned %v", rr.Code)
+	}
+}
+
+// Test CORS headers of actual requests
+func TestCORS_Request(t *testing.T) {
+	router := New()
+	router.Use(CORS(CORSConfig{
+		AllowOrigins:    []string{"*"},
+		AllowOriginFunc: func(origin string) bool { return false },
+		ExposeHeaders:   []string{"X-Total"},
+	}))
+	router.GET("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(expected))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Origin", "https://example.com")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Body.String() != expected {
+		t.Errorf(errorFormat, rr.Body.String(), expected)
+	}
+	if rr.Header().Get("Access-Control-Allow-Origin") != "*" || rr.Header().Get("Access-Control-Expose-Headers") != "X-Total" ||
+		rr.Header().Get("Access-Control-Allow-Credentials") != "" || rr.Header().Get("Vary") != "Origin" {
+		t.Errorf("unexpected headers: %v", rr.Header())
+	}
+
+	// AllowOriginFunc
+	router = New()
+	router.Use(CORS(CORSConfig{AllowOrigins: []string{"https://example.com"}, AllowOriginFunc: func(origin string) bool {
+		return strings.HasSuffix(origin, ".internal")
+	}}))
+	router.GET("/users", func(w http.ResponseWriter, r *http.Request) {})
+	for origin, want := range map[string]string{"http://app.internal": "http://app.internal", "http://app.external": ""} {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("Origin", origin)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Header().Get("Access-Control-Allow-Origin") != want {
+			t.Errorf("origin %v returned %q", origin, rr.Header().Get("Access-Control-Allow-Origin"))
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package gorouter
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// debugTemplate renders the route table for DebugHandler
+var debugTemplate = template.Must(template.New("routes").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gorouter routes</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: .3em .8em; text-align: left; }
+pre { background: #f6f6f6; padding: 1em; }
+</style>
+</head>
+<body>
+<h1>Routes</h1>
+<table>
+<tr><th>Method</th><th>Pattern</th><th>Name</th><th>Middleware</th><th>Metadata</th></tr>
+{{range .Routes}}<tr><td>{{.Method}}</td><td>{{.Pattern}}</td><td>{{.Name}}</td><td>{{.Middleware}}</td><td>{{range $k, $v := .Metadata}}{{$k}}={{$v}} {{end}}</td></tr>
+{{end}}</table>
+<h1>Trees</h1>
+<pre>{{.Dump}}</pre>
+</body>
+</html>
+`))
+
+// String prints the node hierarchy of the tree, one node per line with its
+// depth, isPattern flag and whether a handle is registered
+func (t *Tree) String() string {
+	var b strings.Builder
+	t.root.write(&b, "", "")
+	return b.String()
+}
+
+// write prints the node and its children sorted by key
+func (n *Node) write(b *strings.Builder, indent string, branch string) {
+	fmt.Fprintf(b, "%s%s%s depth=%d pattern=%t handle=%t", indent, branch, n.key, n.depth, n.isPattern, n.handle != nil)
+	if n.handle != nil {
+		fmt.Fprintf(b, " path=/%s middleware=%d", trimPathPrefix(n.path), len(n.middleware))
+	}
+	b.WriteString("\n")
+
+	switch branch {
+	case "├── ":
+		indent += "│   "
+	case "└── ":
+		indent += "    "
+	}
+
+	keys := make([]string, 0, len(n.children))
+	for key := range n.children {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		if i == len(keys)-1 {
+			n.children[key].write(b, indent, "└── ")
+		} else {
+			n.children[key].write(b, indent, "├── ")
+		}
+	}
+}
+
+// Dump prints the tree of every method followed by the mounted handlers
+// 打印路由树, 用于排查 404
+func (r *Router) Dump(w io.Writer) error {
+	snapshot := r.table.load()
+
+	methods := make([]string, 0, len(snapshot.trees))
+	for method := range snapshot.trees {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		if _, err := fmt.Fprintf(w, "%s\n%s\n", method, snapshot.trees[method]); err != nil {
+			return err
+		}
+	}
+
+	prefixes := make([]string, 0, len(snapshot.mounts))
+	for prefix := range snapshot.mounts {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	for _, prefix := range prefixes {
+		if _, err := fmt.Fprintf(w, "MOUNT %s/\n", prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DebugHandler returns a handler rendering the route table, it is meant to be
+// registered on a debug path, e.g. router.GET("/debug/routes", router.DebugHandler().ServeHTTP).
+// The format is picked by the `format` query parameter (text, json or html)
+// or else by the Accept header, defaulting to text.
+func (r *Router) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		format := req.URL.Query().Get("format")
+		if format == "" {
+			accept := req.Header.Get("Accept")
+			switch {
+			case strings.Contains(accept, "application/json"):
+				format = "json"
+			case strings.Contains(accept, "text/html"):
+				format = "html"
+			}
+		}
+
+		switch format {
+		case "json":
+			data, err := json.MarshalIndent(r.Routes(), "", "  ")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(data)
+		case "html":
+			var dump strings.Builder
+			r.Dump(&dump)
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			debugTemplate.Execute(w, struct {
+				Routes []RouteInfo
+				Dump   string
+			}{r.Routes(), dump.String()})
+		default:
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			for _, route := range r.Routes() {
+				fmt.Fprintf(w, "%-7s %s", route.Method, route.Pattern)
+				if route.Name != "" {
+					fmt.Fprintf(w, " name=%s", route.Name)
+				}
+				fmt.Fprintf(w, " middleware=%d\n", route.Middleware)
+			}
+			fmt.Fprintln(w)
+			r.Dump(w)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package gorouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test Tree.String
+func TestTree_String(t *testing.T) {
+	tree := NewTree()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	tree.Add("/users/:id", handler)
+	tree.Add("/users", handler)
+	tree.Add("/about", handler)
+
+	expected := `/ depth=1 pattern=false handle=false
+├── about depth=2 pattern=true handle=true path=/about middleware=0
+└── users depth=2 pattern=true handle=true path=/users middleware=0
+    └── :id depth=3 pattern=true handle=true path=/users/:id middleware=0
+`
+	if tree.String() != expected {
+		t.Errorf("Tree.String returned unexpected output: got\n%v\nwant\n%v", tree.String(), expected)
+	}
+}
+
+// Test DebugHandler
+func TestRouter_DebugHandler(t *testing.T) {
+	router := New()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	router.GETAndName("/users/:id", handler, "user")
+	router.Mount("/admin", http.NotFoundHandler())
+	router.GET("/debug/routes", router.DebugHandler().ServeHTTP)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+	for _, want := range []string{"GET     /users/:id name=user", ":id depth=3 pattern=true handle=true", "MOUNT /admin/"} {
+		if !strings.Contains(rr.Body.String(), want) {
+			t.Errorf("text output misses %q:\n%v", want, rr.Body.String())
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/debug/routes?format=json", nil))
+	var routes []RouteInfo
+	if err := json.Unmarshal(rr.Body.Bytes(), &routes); err != nil || len(routes) != 2 || routes[1].Name != "user" {
+		t.Errorf("json output is unexpected: %v %v", err, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/debug/routes", nil)
+	req.Header.Set("Accept", "text/html")
+	router.ServeHTTP(rr, req)
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") || !strings.Contains(rr.Body.String(), "<td>/users/:id</td>") {
+		t.Errorf("html output is unexpected: %v %v", ct, rr.Body.String())
+	}
+}
@@ -0,0 +1,75 @@
+package gorouter
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+//go:embed assets/docs.html
+var assets embed.FS
+
+// docsTemplate is the self-contained API explorer served by ServeDocs
+var docsTemplate = template.Must(template.ParseFS(assets, "assets/docs.html"))
+
+// ServeDocs mounts the generated OpenAPI document and an API explorer below
+// `prefix`: `prefix/` serves the explorer page, `prefix/openapi.json` and
+// `prefix/openapi.yaml` the document. The explorer has no external assets so
+// it works offline. The document is generated per request and always
+// reflects the current route table.
+// 挂载 API 文档页面
+func (r *Router) ServeDocs(prefix string, info ...OpenAPIInfo) {
+	d := &docsHandler{
+		router: r,
+		info:   OpenAPIInfo{Title: "API", Version: "0.0.0"},
+	}
+	if len(info) > 0 {
+		d.info = info[0]
+	}
+	r.Mount(prefix, d)
+}
+
+// docsHandler serves the explorer and the OpenAPI document of a router
+type docsHandler struct {
+	router *Router
+	info   OpenAPIInfo
+}
+
+// ServeHTTP makes the docsHandler implement the http.Handler interface.
+func (d *docsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch req.URL.Path {
+	case "/":
+		// 页面使用相对地址加载文档, 需要以 `/` 结尾
+		if requestPath := strings.SplitN(req.RequestURI, "?", 2)[0]; requestPath != "" && !strings.HasSuffix(requestPath, "/") {
+			http.Redirect(w, req, requestPath+"/", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		docsTemplate.Execute(w, d.info)
+	case "/openapi.json":
+		data, err := d.router.OpenAPI(d.info).JSON()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	case "/openapi.yaml":
+		data, err := d.router.OpenAPI(d.info).YAML()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(data)
+	default:
+		http.NotFound(w, req)
+	}
+}
@@ -0,0 +1,81 @@
+package gorouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test ServeDocs
+func TestRouter_ServeDocs(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {}).Summary("Get a user")
+	router.ServeDocs("/docs", OpenAPIInfo{Title: "Users API", Version: "1.2.0"})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if rr.Code != http.StatusMovedPermanently || rr.Header().Get("Location") != "/docs/" {
+		t.Errorf("handler returned wrong redirect: got %v %v", rr.Code, rr.Header().Get("Location"))
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+	body := rr.Body.String()
+	if !strings.Contains(body, "<title>Users API</title>") || !strings.Contains(body, `fetch(base + "openapi.json")`) {
+		t.Errorf("unexpected explorer page: %v", body)
+	}
+	if strings.Contains(body, "://") {
+		t.Error("explorer page references external assets")
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	var doc OpenAPIDocument
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Info.Title != "Users API" || doc.Paths["/users/{id}"]["get"].Summary != "Get a user" || len(doc.Paths) != 1 {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs/openapi.yaml", nil))
+	if ct := rr.Header().Get("Content-Type"); ct != "application/yaml" || !strings.HasPrefix(rr.Body.String(), "info:\n") {
+		t.Errorf("unexpected yaml document: %v %v", ct, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/docs/openapi.json", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+// Test the explorer handles request bodies without a JSON media type
+func TestRouter_ServeDocsConsumes(t *testing.T) {
+	router := New()
+	router.POST("/users", func(w http.ResponseWriter, r *http.Request) {}).
+		RequestBody(openAPIUser{}).Consumes("application/xml")
+	router.ServeDocs("/docs", OpenAPIInfo{Title: "Users API", Version: "1.2.0"})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	var doc OpenAPIDocument
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	content := doc.Paths["/users"]["post"].RequestBody.Content
+	if _, ok := content["application/xml"]; !ok || len(content) != 1 {
+		t.Errorf("unexpected request body content: %v", content)
+	}
+
+	// 请求体的媒体类型取自文档, 不假定为 JSON
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+	body := rr.Body.String()
+	if strings.Contains(body, `content["application/json"].schema`) || !strings.Contains(body, "mediaType(op.requestBody.content)") {
+		t.Errorf("explorer assumes a JSON request body: %v", body)
+	}
+}
@@ -0,0 +1,180 @@
+package gorouter
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+)
+
+type (
+	// HandlerE is a handler returning an error, a non nil error is written
+	// by the router's ErrorHandler
+	HandlerE func(w http.ResponseWriter, r *http.Request) error
+
+	// HTTPError is an error carrying the response status, a machine readable
+	// code and a message safe to show to clients
+	HTTPError struct {
+		// Status is the http status code of the response
+		Status int `json:"status"`
+		// Code is an optional application specific error code
+		Code string `json:"code,omitempty"`
+		// Message is the message written to the client
+		Message string `json:"message"`
+		// Err is the optional underlying error, it is never written to the client
+		Err error `json:"-"`
+	}
+)
+
+// sentinelErrors maps well known errors to the response written by the default ErrorHandler
+var sentinelErrors = []struct {
+	err    error
+	status int
+}{
+	{ErrNotFoundRouter, http.StatusNotFound},
+	{ErrNotFoundMethod, http.StatusMethodNotAllowed},
+	{ErrGenerateParameters, http.StatusBadRequest},
+	{fs.ErrNotExist, http.StatusNotFound},
+	{fs.ErrPermission, http.StatusForbidden},
+	{http.ErrHandlerTimeout, http.StatusServiceUnavailable},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout},
+}
+
+// Error implements the error interface
+func (e *HTTPError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.Status)
+	}
+	if e.Code != "" {
+		message = e.Code + ": " + message
+	}
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	return message
+}
+
+// Unwrap returns the underlying error
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// GETE adds the route `path` that matches a GET http method to
+// execute the `handle` HandlerE.
+func (r *Router) GETE(path string, handle HandlerE) *Route {
+	return r.HandleE(http.MethodGet, path, handle)
+}
+
+// POSTE adds the route `path` that matches a POST http method to
+// execute the `handle` HandlerE.
+func (r *Router) POSTE(path string, handle HandlerE) *Route {
+	return r.HandleE(http.MethodPost, path, handle)
+}
+
+// DELETEE adds the route `path` that matches a DELETE http method to
+// execute the `handle` HandlerE.
+func (r *Router) DELETEE(path string, handle HandlerE) *Route {
+	return r.HandleE(http.MethodDelete, path, handle)
+}
+
+// PUTE adds the route `path` that matches a PUT http method to
+// execute the `handle` HandlerE.
+func (r *Router) PUTE(path string, handle HandlerE) *Route {
+	return r.HandleE(http.MethodPut, path, handle)
+}
+
+// PATCHE adds the route `path` that matches a PATCH http method to
+// execute the `handle` HandlerE.
+func (r *Router) PATCHE(path string, handle HandlerE) *Route {
+	return r.HandleE(http.MethodPatch, path, handle)
+}
+
+// HandleE register a new error returning handler with the given path and method.
+// 注册返回 error 的 handler, 错误统一由 ErrorHandler 处理
+func (r *Router) HandleE(method string, path string, handle HandlerE) *Route {
+	return r.Handle(method, path, func(w http.ResponseWriter, req *http.Request) {
+		if err := handle(w, req); err != nil {
+			r.HandleError(w, req, err)
+		}
+	})
+}
+
+// HandleError writes `err` with the custom ErrorHandler, or with the default
+// one which answers with the status of an *HTTPError, a *Problem or a known
+// sentinel error and 500 otherwise
+func (r *Router) HandleError(w http.ResponseWriter, req *http.Request, err error) {
+	if r.ErrorHandler != nil {
+		r.ErrorHandler(w, req, err)
+		return
+	}
+
+	if r.ProblemDetails {
+		WriteProblem(w, req, ProblemFromError(err))
+		return
+	}
+
+	httpErr := ErrorStatus(err)
+	message := httpErr.Message
+	if message == "" {
+		message = http.StatusText(httpErr.Status)
+	}
+	http.Error(w, message, httpErr.Status)
+}
+
+// handleError writes `err` with the HandleError of the router serving w, found
+// through Unwrap, so middleware errors honor ErrorHandler and ProblemDetails.
+// Outside a router the default plain text response is written.
+func handleError(w http.ResponseWriter, req *http.Request, err error) {
+	var router *Router
+	walkResponseWriters(w, func(rw *ResponseWriter) bool {
+		router = rw.router
+		return router == nil
+	})
+	if router != nil {
+		router.HandleError(w, req, err)
+		return
+	}
+	httpErr := ErrorStatus(err)
+	http.Error(w, http.StatusText(httpErr.Status), httpErr.Status)
+}
+
+// ErrorStatus returns `err` as an *HTTPError, a *Problem, ValidationErrors and known
+// sentinel errors get their status and any other error becomes a 500 whose message hides the cause.
+// An *HTTPError or *Problem without a valid status is reported as a 500.
+func ErrorStatus(err error) *HTTPError {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		if !validStatus(httpErr.Status) {
+			withStatus := *httpErr
+			withStatus.Status = http.StatusInternalServerError
+			return &withStatus
+		}
+		return httpErr
+	}
+
+	var problem *Problem
+	if errors.As(err, &problem) {
+		status := problem.Status
+		if !validStatus(status) {
+			status = http.StatusInternalServerError
+		}
+		return &HTTPError{Status: status, Message: problem.Detail, Err: err}
+	}
+
+	if errs, ok := asValidationErrors(err); ok {
+		return &HTTPError{Status: http.StatusUnprocessableEntity, Message: errs.Error(), Err: err}
+	}
+
+	for _, sentinel := range sentinelErrors {
+		if errors.Is(err, sentinel.err) {
+			return &HTTPError{Status: sentinel.status, Message: http.StatusText(sentinel.status), Err: err}
+		}
+	}
+	return &HTTPError{Status: http.StatusInternalServerError, Message: http.StatusText(http.StatusInternalServerError), Err: err}
+}
+
+// validStatus reports whether status can be written by http.ResponseWriter.WriteHeader
+func validStatus(status int) bool {
+	return status >= 100 && status <= 999
+}
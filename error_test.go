@@ -0,0 +1,80 @@
+package gorouter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Test HandleE with the default ErrorHandler
+func TestRouter_HandleE(t *testing.T) {
+	router := New()
+
+	router.GETE("/ok", func(w http.ResponseWriter, r *http.Request) error {
+		fmt.Fprint(w, expected)
+		return nil
+	})
+	router.POSTE("/teapot", func(w http.ResponseWriter, r *http.Request) error {
+		return &HTTPError{Status: http.StatusTeapot, Code: "teapot", Message: "short and stout"}
+	})
+	router.PUTE("/missing", func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("load user: %w", ErrNotFoundRouter)
+	})
+	router.DELETEE("/broken", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("database password is hunter2")
+	})
+	router.GETE("/nostatus", func(w http.ResponseWriter, r *http.Request) error {
+		return &HTTPError{Message: "bad"}
+	})
+	router.POSTE("/noproblemstatus", func(w http.ResponseWriter, r *http.Request) error {
+		return &Problem{Detail: "bad"}
+	})
+
+	tests := []struct {
+		method, url string
+		code        int
+		body        string
+	}{
+		{http.MethodGet, "/ok", http.StatusOK, expected},
+		{http.MethodPost, "/teapot", http.StatusTeapot, "short and stout\n"},
+		{http.MethodPut, "/missing", http.StatusNotFound, "Not Found\n"},
+		{http.MethodDelete, "/broken", http.StatusInternalServerError, "Internal Server Error\n"},
+		{http.MethodGet, "/nostatus", http.StatusInternalServerError, "bad\n"},
+		{http.MethodPost, "/noproblemstatus", http.StatusInternalServerError, "bad\n"},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(test.method, test.url, nil))
+		if rr.Code != test.code || rr.Body.String() != test.body {
+			t.Errorf("%v %v returned %v %q, want %v %q", test.method, test.url, rr.Code, rr.Body.String(), test.code, test.body)
+		}
+	}
+}
+
+// Test custom ErrorHandler
+func TestRouter_ErrorHandler(t *testing.T) {
+	router := New()
+
+	var handled error
+	router.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+		w.WriteHeader(ErrorStatus(err).Status)
+	}
+
+	errCustom := &HTTPError{Status: http.StatusConflict, Code: "conflict"}
+	router.Group("/api").PATCHE("/users", func(w http.ResponseWriter, r *http.Request) error {
+		return errCustom
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPatch, "/api/users", nil))
+	if handled != errCustom || rr.Code != http.StatusConflict {
+		t.Errorf("ErrorHandler got %v and wrote %v", handled, rr.Code)
+	}
+	if errCustom.Error() != "conflict: Conflict" {
+		t.Errorf("unexpected error message: %v", errCustom.Error())
+	}
+}
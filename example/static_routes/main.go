@@ -1,15 +1,29 @@
 package main
 
 import (
+	"embed"
 	"gorouter"
+	"io/fs"
 	"log"
 	"net/http"
 )
 
+//go:embed public
+var public embed.FS
+
 func main() {
+	files, err := fs.Sub(public, "public")
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	mux := gorouter.New()
 	mux.GET("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write(([]byte("Hello world")))
 	})
+	// /static/css/app.css, /static/ 列出目录
+	mux.ServeFiles("/static", files, gorouter.StaticConfig{Browse: true})
+	// /app/any/client/route 回退到 index.html
+	mux.ServeFiles("/app", files, gorouter.StaticConfig{SPA: true})
 	log.Fatal(http.ListenAndServe(":8000", mux))
 }
@@ -1,5 +1,7 @@
 module gorouter
 
+go 1.21
+
 require (
 	github.com/mattn/goveralls v0.0.2 // indirect
 	github.com/xujiajun/gorouter v1.2.0
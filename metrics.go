@@ -0,0 +1,247 @@
+package gorouter
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MetricsContentType is the media type of the Prometheus text exposition format
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	// DefaultDurationBuckets are the latency histogram buckets in seconds
+	DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	// DefaultSizeBuckets are the response size histogram buckets in bytes
+	DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+)
+
+// MetricsConfig configures NewMetrics
+type MetricsConfig struct {
+	// Namespace prefixes the metric names, defaults to `gorouter`
+	Namespace string
+	// DurationBuckets are the latency buckets in seconds, defaults to DefaultDurationBuckets
+	DurationBuckets []float64
+	// SizeBuckets are the response size buckets in bytes, defaults to DefaultSizeBuckets
+	SizeBuckets []float64
+}
+
+// Metrics collects request counts, in-flight requests, latencies and
+// response sizes labeled by method, route pattern and status class. Requests
+// matching no route are labeled with an empty route so paths can't explode
+// the cardinality.
+//
+//	metrics := gorouter.NewMetrics()
+//	router.Use(metrics.Middleware())
+//	router.Mount("/metrics", metrics.Handler())
+type Metrics struct {
+	config   MetricsConfig
+	inFlight int64
+	mu       sync.Mutex
+	series   map[metricLabels]*metricSeries
+}
+
+// metricLabels are the labels of a request series
+type metricLabels struct {
+	method string
+	route  string
+	status string
+}
+
+// metricSeries records the requests of one label set
+type metricSeries struct {
+	count    uint64
+	duration histogram
+	size     histogram
+}
+
+// histogram records cumulative bucket counts
+type histogram struct {
+	counts []uint64
+	sum    float64
+}
+
+// NewMetrics returns a Metrics collector
+// 基于路由模式的 Prometheus 指标
+func NewMetrics(config ...MetricsConfig) *Metrics {
+	var c MetricsConfig
+	if len(config) > 0 {
+		c = config[0]
+	}
+	if c.Namespace == "" {
+		c.Namespace = "gorouter"
+	}
+	if len(c.DurationBuckets) == 0 {
+		c.DurationBuckets = DefaultDurationBuckets
+	}
+	if len(c.SizeBuckets) == 0 {
+		c.SizeBuckets = DefaultSizeBuckets
+	}
+	c.DurationBuckets = sortedBuckets(c.DurationBuckets)
+	c.SizeBuckets = sortedBuckets(c.SizeBuckets)
+	return &Metrics{config: c, series: make(map[metricLabels]*metricSeries)}
+}
+
+// Middleware returns the middleware observing every request, use it with
+// Router.Use or wrap the router to observe unmatched requests too
+func (m *Metrics) Middleware() MiddlewareType {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&m.inFlight, 1)
+			defer atomic.AddInt64(&m.inFlight, -1)
+
+			start := time.Now()
+			rw := NewResponseWriter(w)
+			next(rw, r)
+			m.observe(rw, r, time.Since(start))
+		}
+	}
+}
+
+// observe records a served request
+func (m *Metrics) observe(rw *ResponseWriter, r *http.Request, latency time.Duration) {
+	status := rw.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+	labels := metricLabels{method: r.Method, status: strconv.Itoa(status/100) + "xx"}
+	if node := matchedNode(rw); node != nil {
+		labels.route = "/" + trimPathPrefix(node.path)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	series, ok := m.series[labels]
+	if !ok {
+		series = &metricSeries{
+			duration: histogram{counts: make([]uint64, len(m.config.DurationBuckets))},
+			size:     histogram{counts: make([]uint64, len(m.config.SizeBuckets))},
+		}
+		m.series[labels] = series
+	}
+	series.count++
+	series.duration.observe(m.config.DurationBuckets, latency.Seconds())
+	series.size.observe(m.config.SizeBuckets, float64(rw.Size()))
+}
+
+// observe adds v to the buckets it falls in
+func (h *histogram) observe(buckets []float64, v float64) {
+	for i, bound := range buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+}
+
+// Handler returns a handler rendering the metrics in the Prometheus text exposition format
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", MetricsContentType)
+		w.Write(m.expose())
+	})
+}
+
+// expose renders the metrics in the text exposition format
+func (m *Metrics) expose() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	labels := make([]metricLabels, 0, len(m.series))
+	for l := range m.series {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].route != labels[j].route {
+			return labels[i].route < labels[j].route
+		}
+		if labels[i].method != labels[j].method {
+			return labels[i].method < labels[j].method
+		}
+		return labels[i].status < labels[j].status
+	})
+
+	var b bytes.Buffer
+	ns := m.config.Namespace
+
+	name := ns + "_http_requests_total"
+	writeMetricHeader(&b, name, "counter", "Total number of HTTP requests.")
+	for _, l := range labels {
+		b.WriteString(name + l.String("") + " " + strconv.FormatUint(m.series[l].count, 10) + "\n")
+	}
+
+	name = ns + "_http_requests_in_flight"
+	writeMetricHeader(&b, name, "gauge", "Number of HTTP requests being served.")
+	b.WriteString(name + " " + strconv.FormatInt(atomic.LoadInt64(&m.inFlight), 10) + "\n")
+
+	name = ns + "_http_request_duration_seconds"
+	writeMetricHeader(&b, name, "histogram", "HTTP request latencies in seconds.")
+	for _, l := range labels {
+		series := m.series[l]
+		writeHistogram(&b, name, l, m.config.DurationBuckets, series.duration, series.count)
+	}
+
+	name = ns + "_http_response_size_bytes"
+	writeMetricHeader(&b, name, "histogram", "HTTP response sizes in bytes.")
+	for _, l := range labels {
+		series := m.series[l]
+		writeHistogram(&b, name, l, m.config.SizeBuckets, series.size, series.count)
+	}
+	return b.Bytes()
+}
+
+// String returns the label set in the exposition format, `le` is added when not empty
+func (l metricLabels) String(le string) string {
+	s := `{method="` + escapeLabel(l.method) + `",route="` + escapeLabel(l.route) + `",status="` + l.status + `"`
+	if le != "" {
+		s += `,le="` + le + `"`
+	}
+	return s + "}"
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a metric
+func writeMetricHeader(b *bytes.Buffer, name string, kind string, help string) {
+	b.WriteString("# HELP " + name + " " + help + "\n")
+	b.WriteString("# TYPE " + name + " " + kind + "\n")
+}
+
+// writeHistogram writes the buckets, sum and count of a histogram
+func writeHistogram(b *bytes.Buffer, name string, l metricLabels, buckets []float64, h histogram, count uint64) {
+	for i, bound := range buckets {
+		b.WriteString(name + "_bucket" + l.String(formatFloat(bound)) + " " + strconv.FormatUint(h.counts[i], 10) + "\n")
+	}
+	b.WriteString(name + "_bucket" + l.String("+Inf") + " " + strconv.FormatUint(count, 10) + "\n")
+	b.WriteString(name + "_sum" + l.String("") + " " + formatFloat(h.sum) + "\n")
+	b.WriteString(name + "_count" + l.String("") + " " + strconv.FormatUint(count, 10) + "\n")
+}
+
+// escapeLabel escapes a label value for the exposition format
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// formatFloat formats a sample value
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedBuckets returns a sorted copy of buckets without +Inf, which is always added
+func sortedBuckets(buckets []float64) []float64 {
+	sorted := make([]float64, 0, len(buckets))
+	for _, bound := range buckets {
+		if !math.IsInf(bound, 1) {
+			sorted = append(sorted, bound)
+		}
+	}
+	sort.Float64s(sorted)
+	return sorted
+}
@@ -0,0 +1,71 @@
+package gorouter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test Metrics middleware and exposition
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics(MetricsConfig{Namespace: "app", DurationBuckets: []float64{10, 1}, SizeBuckets: []float64{10}})
+	router := New()
+	router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(expected))
+	})
+	router.POST("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	router.Mount("/metrics", metrics.Handler())
+	handler := metrics.Middleware()(router.ServeHTTP)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/users/1", nil),
+		httptest.NewRequest(http.MethodGet, "/users/2", nil),
+		httptest.NewRequest(http.MethodPost, "/users", nil),
+		httptest.NewRequest(http.MethodGet, "/missing", nil),
+	} {
+		handler(httptest.NewRecorder(), req)
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Header().Get("Content-Type") != MetricsContentType {
+		t.Errorf("metrics returned content type %v", rr.Header().Get("Content-Type"))
+	}
+	body, _ := io.ReadAll(rr.Body)
+	for _, line := range []string{
+		"# TYPE app_http_requests_total counter",
+		`app_http_requests_total{method="GET",route="/users/:id",status="2xx"} 2`,
+		`app_http_requests_total{method="POST",route="/users",status="4xx"} 1`,
+		`app_http_requests_total{method="GET",route="",status="4xx"} 1`,
+		"# TYPE app_http_requests_in_flight gauge",
+		"app_http_requests_in_flight 0",
+		"# TYPE app_http_request_duration_seconds histogram",
+		`app_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="2xx",le="1"} 2`,
+		`app_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="2xx",le="10"} 2`,
+		`app_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="2xx",le="+Inf"} 2`,
+		`app_http_request_duration_seconds_count{method="GET",route="/users/:id",status="2xx"} 2`,
+		`app_http_response_size_bytes_bucket{method="GET",route="/users/:id",status="2xx",le="10"} 0`,
+		`app_http_response_size_bytes_sum{method="GET",route="/users/:id",status="2xx"} 24`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("metrics missing %q in:\n%s", line, body)
+		}
+	}
+}
+
+// Test the in-flight gauge while a request is served
+func TestMetrics_InFlight(t *testing.T) {
+	metrics := NewMetrics()
+	var exposed string
+	handler := metrics.Middleware()(func(w http.ResponseWriter, r *http.Request) {
+		exposed = string(metrics.expose())
+	})
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.Contains(exposed, "gorouter_http_requests_in_flight 1\n") {
+		t.Errorf("in-flight gauge not set while serving:\n%s", exposed)
+	}
+}
@@ -0,0 +1,95 @@
+package gorouter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// mountKeyType is a private struct that is used for storing the mount prefix in net.Context
+type mountKeyType struct{}
+
+// mountKey is the key that is used to store the stripped mount prefix for each request
+var mountKey = mountKeyType{}
+
+// Mount attaches handler to `prefix`, every request whose path is the prefix
+// or lies beneath it is forwarded to the handler whatever its method.
+// The prefix is stripped from URL.Path before the handler runs, the original
+// prefix can be read back with MountPrefix.
+// Routes registered with Handle take precedence over mounted handlers.
+// 挂载任意 http.Handler 到前缀下, 例如另一个 Router 或 http.FileServer
+func (r *Router) Mount(prefix string, handler http.Handler) {
+	if handler == nil {
+		panic("gorouter: nil handler")
+	}
+
+	prefix = cleanPath(r.prefix + "/" + prefix)
+
+	middleware := append([]MiddlewareType(nil), r.middleware...)
+	mounted := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		handle(w, req, handler.ServeHTTP, middleware)
+	})
+	r.table.update(func(s *routeSnapshot) {
+		s.mounts[prefix] = mounted
+	})
+}
+
+// MountPrefix returns the prefix stripped from the request path by Mount.
+// Nested mounts are concatenated, so the value is always relative to the
+// outermost router. It returns an empty string outside a mounted handler.
+func MountPrefix(r *http.Request) string {
+	if prefix, ok := r.Context().Value(mountKey).(string); ok {
+		return prefix
+	}
+	return ""
+}
+
+// serveMount dispatches req to the mounted handler with the longest matching
+// prefix and reports whether one was found.
+func (s *routeSnapshot) serveMount(w http.ResponseWriter, req *http.Request) bool {
+	var (
+		matched string
+		handler http.Handler
+	)
+
+	requestUrl := req.URL.Path
+	for prefix, h := range s.mounts {
+		if handler != nil && len(prefix) <= len(matched) {
+			continue
+		}
+		if prefix == "" || requestUrl == prefix || strings.HasPrefix(requestUrl, prefix+"/") {
+			matched, handler = prefix, h
+		}
+	}
+
+	if handler == nil {
+		return false
+	}
+
+	handler.ServeHTTP(w, stripMountPrefix(req, matched))
+	return true
+}
+
+// stripMountPrefix returns a shallow copy of req with `prefix` removed from
+// its URL path and recorded in the request context.
+func stripMountPrefix(req *http.Request, prefix string) *http.Request {
+	ctx := context.WithValue(req.Context(), mountKey, MountPrefix(req)+prefix)
+	r2 := req.WithContext(ctx)
+
+	r2.URL = new(url.URL)
+	*r2.URL = *req.URL
+	r2.URL.Path = ensureLeadingSlash(strings.TrimPrefix(req.URL.Path, prefix))
+	if req.URL.RawPath != "" {
+		r2.URL.RawPath = ensureLeadingSlash(strings.TrimPrefix(req.URL.RawPath, prefix))
+	}
+	return r2
+}
+
+// ensureLeadingSlash makes sure the stripped path is still absolute
+func ensureLeadingSlash(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return "/" + path
+	}
+	return path
+}
@@ -0,0 +1,106 @@
+package gorouter
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Test Mount
+func TestRouter_Mount(t *testing.T) {
+	router := New()
+
+	router.Mount("/admin", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Method+" "+MountPrefix(r)+" "+r.URL.Path)
+	}))
+
+	tests := []struct {
+		method, url, want string
+	}{
+		{http.MethodGet, "/admin", "GET /admin /"},
+		{http.MethodGet, "/admin/", "GET /admin /"},
+		{http.MethodPost, "/admin/users/1", "POST /admin /users/1"},
+		{http.MethodOptions, "/admin/users", "OPTIONS /admin /users"},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest(test.method, test.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		router.ServeHTTP(rr, req)
+		if rr.Body.String() != test.want {
+			t.Errorf(errorFormat, rr.Body.String(), test.want)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/administrator", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+// Test Mount with a nested router, a group prefix and route precedence
+func TestRouter_MountRouter(t *testing.T) {
+	router := New()
+	admin := New()
+
+	admin.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, MountPrefix(r)+" "+GetParam(r, "id"))
+	})
+	router.Group("/api").Mount("/admin/", admin)
+	router.GET("/api/admin/health", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/api/admin/users/7", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(rr, req)
+	if expected := "/api/admin 7"; rr.Body.String() != expected {
+		t.Errorf(errorFormat, rr.Body.String(), expected)
+	}
+
+	rr = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodGet, "/api/admin/health", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(rr, req)
+	if expected := "ok"; rr.Body.String() != expected {
+		t.Errorf(errorFormat, rr.Body.String(), expected)
+	}
+}
+
+// Test Mount longest prefix wins
+func TestRouter_MountLongestPrefix(t *testing.T) {
+	router := New()
+
+	router.Mount("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "root")
+	}))
+	router.Mount("/static", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "static")
+	}))
+
+	for url, want := range map[string]string{"/static/app.js": "static", "/other": "root"} {
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		router.ServeHTTP(rr, req)
+		if rr.Body.String() != want {
+			t.Errorf(errorFormat, rr.Body.String(), want)
+		}
+	}
+}
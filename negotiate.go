@@ -0,0 +1,137 @@
+package gorouter
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// acceptRange is a media range of the Accept header
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// Consumes declares the media types accepted in the request body, requests
+// with a body of another Content-Type are answered with 415 Unsupported Media
+// Type. Wildcards such as `application/*` are allowed.
+func (rt *Route) Consumes(mediaTypes ...string) *Route {
+	return rt.update(func(tree *Tree, node *Node) {
+		node.consumes = append([]string(nil), mediaTypes...)
+	})
+}
+
+// Produces declares the media types the route responds with, requests whose
+// Accept header matches none of them are answered with 406 Not Acceptable.
+// Handlers pick the response type with NegotiateContentType.
+func (rt *Route) Produces(mediaTypes ...string) *Route {
+	return rt.update(func(tree *Tree, node *Node) {
+		node.produces = append([]string(nil), mediaTypes...)
+	})
+}
+
+// NegotiateContentType returns the offer best matching the Accept header of
+// the request by q-value, then by the specificity of the matching range and
+// then by the order of the offers. It returns the first offer when there is
+// no Accept header and an empty string when no offer is acceptable.
+// 根据 Accept 的 q 值选择响应类型
+func NegotiateContentType(r *http.Request, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	header := strings.Join(r.Header.Values("Accept"), ",")
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(header)
+	var (
+		best            string
+		bestQ           float64
+		bestSpecificity = -1
+	)
+	for _, offer := range offers {
+		q, specificity := -1.0, -1
+		for _, accept := range ranges {
+			if s := matchMediaRange(accept.mediaType, offer); s > specificity {
+				q, specificity = accept.q, s
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		if q > bestQ || q == bestQ && specificity > bestSpecificity {
+			best, bestQ, bestSpecificity = offer, q, specificity
+		}
+	}
+	return best
+}
+
+// parseAccept parses the media ranges and q-values of an Accept header
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// matchMediaRange returns how specifically `mediaRange` matches `mediaType`:
+// -1 for no match, 0 for `*/*`, 1 for `type/*` and 2 for an exact match
+func matchMediaRange(mediaRange string, mediaType string) int {
+	mediaType = strings.ToLower(strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0]))
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	}
+	return -1
+}
+
+// checkContentTypes answers 415 or 406 when the request doesn't satisfy the
+// Consumes or Produces declarations of the node, it reports whether the request may proceed
+func (r *Router) checkContentTypes(w http.ResponseWriter, req *http.Request, node *Node) bool {
+	if len(node.consumes) > 0 && hasBody(req) {
+		mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		supported := false
+		for _, consume := range node.consumes {
+			supported = supported || err == nil && matchMediaRange(strings.ToLower(consume), mediaType) >= 0
+		}
+		if !supported {
+			w.Header().Set("Accept", strings.Join(node.consumes, ", "))
+			r.HandleError(w, req, &HTTPError{
+				Status:  http.StatusUnsupportedMediaType,
+				Message: "supported content types are " + strings.Join(node.consumes, ", "),
+			})
+			return false
+		}
+	}
+
+	if len(node.produces) > 0 && NegotiateContentType(req, node.produces...) == "" {
+		r.HandleError(w, req, &HTTPError{
+			Status:  http.StatusNotAcceptable,
+			Message: "available content types are " + strings.Join(node.produces, ", "),
+		})
+		return false
+	}
+	return true
+}
+
+// hasBody reports whether the request carries a body
+func hasBody(req *http.Request) bool {
+	return req.ContentLength > 0 || len(req.TransferEncoding) > 0 || req.ContentLength < 0 && req.Body != nil && req.Body != http.NoBody
+}
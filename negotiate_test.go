@@ -0,0 +1,97 @@
+package gorouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test NegotiateContentType q-values and specificity
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"application/json", "application/xml", "text/html"}
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"application/xml", "application/xml"},
+		{"text/*", "text/html"},
+		{"application/json;q=0.5, application/xml", "application/xml"},
+		{"application/*;q=0.8, text/html;q=0.9", "text/html"},
+		{"*/*;q=0.1, application/json;q=0", "application/xml"},
+		{"application/xml, application/json", "application/json"},
+		{"image/png", ""},
+		{"application/json;q=2, text/html", "text/html"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+		if got := NegotiateContentType(req, offers...); got != test.want {
+			t.Errorf("Accept %q negotiated %q want %q", test.accept, got, test.want)
+		}
+	}
+}
+
+// Test Consumes and Produces declarations answer 415 and 406
+func TestRoute_ConsumesProduces(t *testing.T) {
+	router := New()
+
+	router.POST("/users", func(w http.ResponseWriter, r *http.Request) {
+		contentType := NegotiateContentType(r, "application/json", "application/xml")
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(contentType))
+	}).Consumes("application/json", "text/*").Produces("application/json", "application/xml")
+
+	tests := []struct {
+		contentType, accept, body string
+		code                      int
+		want                      string
+	}{
+		{"application/json", "", `{}`, http.StatusOK, "application/json"},
+		{"application/json; charset=utf-8", "application/xml", `{}`, http.StatusOK, "application/xml"},
+		{"text/csv", "*/*", `a,b`, http.StatusOK, "application/json"},
+		{"", "", "", http.StatusOK, "application/json"},
+		{"image/png", "", "hi", http.StatusUnsupportedMediaType, ""},
+		{"", "", "hi", http.StatusUnsupportedMediaType, ""},
+		{"application/json", "text/html", `{}`, http.StatusNotAcceptable, ""},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(test.body))
+		if test.contentType != "" {
+			req.Header.Set("Content-Type", test.contentType)
+		}
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != test.code {
+			t.Errorf("%q %q returned status %v want %v", test.contentType, test.accept, rr.Code, test.code)
+		}
+		if test.want != "" && rr.Body.String() != test.want {
+			t.Errorf(errorFormat, rr.Body.String(), test.want)
+		}
+		if test.code == http.StatusUnsupportedMediaType && rr.Header().Get("Accept") != "application/json, text/*" {
+			t.Errorf("415 returned Accept %q", rr.Header().Get("Accept"))
+		}
+	}
+
+	routes := router.Routes()
+	if len(routes) != 1 || len(routes[0].Consumes) != 2 || len(routes[0].Produces) != 2 {
+		t.Errorf("unexpected routes: %+v", routes)
+	}
+
+	// problem 模式下返回 problem+json
+	router.ProblemDetails = true
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("hi"))
+	req.Header.Set("Content-Type", "image/png")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnsupportedMediaType || rr.Header().Get("Content-Type") != ProblemContentType {
+		t.Errorf("problem mode returned %v %v", rr.Code, rr.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,488 @@
+package gorouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metadata keys used by the OpenAPI route helpers
+const (
+	metaSummary     = "summary"
+	metaDescription = "description"
+	metaTags        = "tags"
+	metaRequest     = "request"
+	metaResponses   = "responses"
+)
+
+type (
+	// OpenAPIInfo is the info object of the generated document
+	OpenAPIInfo struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// OpenAPIDocument is an OpenAPI 3.1 document generated from the route table
+	OpenAPIDocument struct {
+		OpenAPI    string                                  `json:"openapi"`
+		Info       OpenAPIInfo                             `json:"info"`
+		Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+		Components *OpenAPIComponents                      `json:"components,omitempty"`
+	}
+
+	// OpenAPIOperation describes a single route
+	OpenAPIOperation struct {
+		OperationID string                      `json:"operationId,omitempty"`
+		Summary     string                      `json:"summary,omitempty"`
+		Description string                      `json:"description,omitempty"`
+		Tags        []string                    `json:"tags,omitempty"`
+		Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+		RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*OpenAPIResponse `json:"responses"`
+	}
+
+	// OpenAPIParameter describes a path parameter
+	OpenAPIParameter struct {
+		Name     string         `json:"name"`
+		In       string         `json:"in"`
+		Required bool           `json:"required"`
+		Schema   *OpenAPISchema `json:"schema"`
+	}
+
+	// OpenAPIRequestBody describes the request body of an operation
+	OpenAPIRequestBody struct {
+		Required bool                         `json:"required"`
+		Content  map[string]*OpenAPIMediaType `json:"content"`
+	}
+
+	// OpenAPIResponse describes a response of an operation
+	OpenAPIResponse struct {
+		Description string                       `json:"description"`
+		Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+	}
+
+	// OpenAPIMediaType holds the schema of a body
+	OpenAPIMediaType struct {
+		Schema *OpenAPISchema `json:"schema"`
+	}
+
+	// OpenAPIComponents holds the schemas of named Go types
+	OpenAPIComponents struct {
+		Schemas map[string]*OpenAPISchema `json:"schemas"`
+	}
+
+	// OpenAPISchema is the subset of JSON Schema generated from Go types
+	OpenAPISchema struct {
+		Ref                  string                    `json:"$ref,omitempty"`
+		Type                 string                    `json:"type,omitempty"`
+		Format               string                    `json:"format,omitempty"`
+		Pattern              string                    `json:"pattern,omitempty"`
+		Items                *OpenAPISchema            `json:"items,omitempty"`
+		Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+		Required             []string                  `json:"required,omitempty"`
+		AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	}
+)
+
+// Summary sets the OpenAPI summary of the route
+func (rt *Route) Summary(summary string) *Route {
+	return rt.Meta(metaSummary, summary)
+}
+
+// Description sets the OpenAPI description of the route
+func (rt *Route) Description(description string) *Route {
+	return rt.Meta(metaDescription, description)
+}
+
+// Tags sets the OpenAPI tags of the route
+func (rt *Route) Tags(tags ...string) *Route {
+	return rt.Meta(metaTags, tags)
+}
+
+// RequestBody documents the JSON request body of the route with a value of
+// its Go type, e.g. RequestBody(User{})
+func (rt *Route) RequestBody(v interface{}) *Route {
+	return rt.Meta(metaRequest, v)
+}
+
+// Response documents a JSON response of the route with a value of its Go
+// type, v may be nil for responses without body
+func (rt *Route) Response(status int, v interface{}) *Route {
+	return rt.update(func(tree *Tree, node *Node) {
+		previous, _ := node.meta[metaResponses].(map[int]interface{})
+		responses := make(map[int]interface{}, len(previous)+1)
+		for k, v := range previous {
+			responses[k] = v
+		}
+		responses[status] = v
+
+		meta := make(map[string]interface{}, len(node.meta)+1)
+		for k, v := range node.meta {
+			meta[k] = v
+		}
+		meta[metaResponses] = responses
+		node.meta = meta
+	})
+}
+
+// OpenAPI generates an OpenAPI 3.1 document from the registered routes.
+// `:name`, `{name:regex}` and `*name` segments become path parameters whose
+// schema pattern is the regular expression used by the router.
+// 根据路由表生成 OpenAPI 文档
+func (r *Router) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.1.0",
+		Info:    info,
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+	}
+	schemas := &schemaGenerator{components: make(map[string]*OpenAPISchema)}
+
+	for _, route := range r.Routes() {
+		path, parameters := openAPIPath(route.Pattern)
+		op := &OpenAPIOperation{
+			OperationID: route.Name,
+			Parameters:  parameters,
+			Responses:   make(map[string]*OpenAPIResponse),
+		}
+		op.Summary, _ = route.Metadata[metaSummary].(string)
+		op.Description, _ = route.Metadata[metaDescription].(string)
+		op.Tags, _ = route.Metadata[metaTags].([]string)
+
+		if v, ok := route.Metadata[metaRequest]; ok && v != nil {
+			op.RequestBody = &OpenAPIRequestBody{
+				Required: true,
+				Content:  schemas.content(v, route.Consumes),
+			}
+		}
+
+		responses, _ := route.Metadata[metaResponses].(map[int]interface{})
+		for status, v := range responses {
+			response := &OpenAPIResponse{Description: http.StatusText(status)}
+			if v != nil {
+				response.Content = schemas.content(v, route.Produces)
+			}
+			op.Responses[strconv.Itoa(status)] = response
+		}
+		if len(op.Responses) == 0 {
+			op.Responses[strconv.Itoa(http.StatusOK)] = &OpenAPIResponse{Description: http.StatusText(http.StatusOK)}
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*OpenAPIOperation)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+
+	if len(schemas.components) > 0 {
+		doc.Components = &OpenAPIComponents{Schemas: schemas.components}
+	}
+	return doc
+}
+
+// JSON returns the document encoded as indented JSON
+func (doc *OpenAPIDocument) JSON() ([]byte, error) {
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// YAML returns the document encoded as YAML, keys are sorted
+func (doc *OpenAPIDocument) YAML() ([]byte, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	writeYAML(&b, v, "", false)
+	return b.Bytes(), nil
+}
+
+// openAPIPath translates a route pattern into an OpenAPI path template and its parameters
+func openAPIPath(pattern string) (string, []*OpenAPIParameter) {
+	var parameters []*OpenAPIParameter
+
+	segments := splitPattern(pattern)
+	for i, segment := range segments {
+		var name, expr string
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name = segment[1:]
+			expr = defaultPattern
+			if name == idKey {
+				expr = idPattern
+			}
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") && strings.Contains(segment, ":"):
+			res := strings.SplitN(segment[1:len(segment)-1], ":", 2)
+			name, expr = res[0], res[1]
+		case strings.HasPrefix(segment, "*"):
+			name, expr = segment[1:], ".*"
+		default:
+			continue
+		}
+
+		segments[i] = "{" + name + "}"
+		parameters = append(parameters, &OpenAPIParameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &OpenAPISchema{Type: "string", Pattern: "^" + expr + "$"},
+		})
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+// schemaGenerator builds schemas from Go types, named structs are stored as components
+type schemaGenerator struct {
+	components map[string]*OpenAPISchema
+	// names records the component name of each named struct type
+	names map[reflect.Type]string
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// content returns the content for the type of v under each media type,
+// application/json when none is declared
+func (g *schemaGenerator) content(v interface{}, mediaTypes []string) map[string]*OpenAPIMediaType {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/json"}
+	}
+	schema := g.schema(reflect.TypeOf(v))
+	content := make(map[string]*OpenAPIMediaType, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = &OpenAPIMediaType{Schema: schema}
+	}
+	return content
+}
+
+// schema returns the schema of t
+func (g *schemaGenerator) schema(t reflect.Type) *OpenAPISchema {
+	if t == nil {
+		return &OpenAPISchema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return &OpenAPISchema{Type: "string", Format: "date-time"}
+		}
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = g.componentName(t)
+			// 先占位, 防止递归类型无限展开
+			g.components[name] = &OpenAPISchema{}
+			*g.components[name] = *g.object(t)
+		}
+		return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+	}
+	return &OpenAPISchema{}
+}
+
+// componentName reserves a component name for the named struct type t, types
+// sharing a name with an earlier type are qualified by their package, e.g. `v2.User`
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	if g.names == nil {
+		g.names = make(map[reflect.Type]string)
+	}
+	candidates := []string{
+		t.Name(),
+		path.Base(t.PkgPath()) + "." + t.Name(),
+		strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + t.Name(),
+	}
+	name := candidates[len(candidates)-1]
+	for _, candidate := range candidates {
+		if _, taken := g.components[candidate]; !taken {
+			name = candidate
+			break
+		}
+	}
+	for i := 2; ; i++ {
+		if _, taken := g.components[name]; !taken {
+			break
+		}
+		name = candidates[len(candidates)-1] + strconv.Itoa(i)
+	}
+	g.names[t] = name
+	return name
+}
+
+// object returns the inline object schema of the struct type t following its json tags
+func (g *schemaGenerator) object(t reflect.Type) *OpenAPISchema {
+	schema := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		if field.Anonymous && tag[0] == "" {
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				embedded := g.object(fieldType)
+				for name, property := range embedded.Properties {
+					schema.Properties[name] = property
+				}
+				schema.Required = append(schema.Required, embedded.Required...)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag[0] != "" {
+			name = tag[0]
+		}
+		schema.Properties[name] = g.schema(field.Type)
+
+		omitempty := false
+		for _, option := range tag[1:] {
+			omitempty = omitempty || option == "omitempty"
+		}
+		if !omitempty && field.Type.Kind() != reflect.Ptr {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// plainYAMLKey matches keys that can be written without quotes
+var plainYAMLKey = regexp.MustCompile(`^[A-Za-z_/$][A-Za-z0-9_./${}:-]*$`)
+
+// writeYAML writes the JSON value v as block YAML, strings are double quoted
+// which YAML accepts with JSON escaping. inline reports whether the first
+// mapping key continues a `- ` list item line.
+func writeYAML(b *bytes.Buffer, v interface{}, indent string, inline bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			if i > 0 || !inline {
+				b.WriteString(indent)
+			}
+			b.WriteString(yamlKey(key) + ":")
+			writeYAMLValue(b, v[key], indent)
+		}
+	case []interface{}:
+		for _, item := range v {
+			b.WriteString(indent + "- ")
+			switch item := item.(type) {
+			case map[string]interface{}:
+				if len(item) > 0 {
+					writeYAML(b, item, indent+"  ", true)
+					continue
+				}
+			case []interface{}:
+				if len(item) > 0 {
+					b.WriteString("\n")
+					writeYAML(b, item, indent+"  ", false)
+					continue
+				}
+			}
+			b.WriteString(yamlScalar(item) + "\n")
+		}
+	}
+}
+
+// writeYAMLValue writes the value of a mapping entry after its key
+func writeYAMLValue(b *bytes.Buffer, v interface{}, indent string) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) > 0 {
+			b.WriteString("\n")
+			writeYAML(b, value, indent+"  ", false)
+			return
+		}
+	case []interface{}:
+		if len(value) > 0 {
+			b.WriteString("\n")
+			writeYAML(b, value, indent, false)
+			return
+		}
+	}
+	b.WriteString(" " + yamlScalar(v) + "\n")
+}
+
+// yamlKey quotes keys that would not be read back as plain strings
+func yamlKey(key string) string {
+	if plainYAMLKey.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// yamlScalar formats a JSON scalar, empty collections use the flow style
+func yamlScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		var b bytes.Buffer
+		encoder := json.NewEncoder(&b)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(v)
+		return strings.TrimSuffix(b.String(), "\n")
+	case map[string]interface{}:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	}
+	return fmt.Sprint(v)
+}
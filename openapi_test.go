@@ -0,0 +1,145 @@
+package gorouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type openAPIUser struct {
+	ID        int64          `json:"id"`
+	Name      string         `json:"name"`
+	Email     string         `json:"email,omitempty"`
+	Friends   []*openAPIUser `json:"friends,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	password  string
+}
+
+// Test OpenAPI
+func TestRouter_OpenAPI(t *testing.T) {
+	router := New()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	router.GETAndName("/users/:id", handler, "getUser").
+		Summary("Get a user").
+		Tags("users").
+		Response(http.StatusOK, openAPIUser{}).
+		Response(http.StatusNotFound, nil)
+	router.POST("/users", handler).RequestBody(&openAPIUser{}).Response(http.StatusCreated, openAPIUser{})
+	router.PUT("/repos/{owner:\\w+}/:name", handler)
+
+	doc := router.OpenAPI(OpenAPIInfo{Title: "users", Version: "1.0.0"})
+
+	get := doc.Paths["/users/{id}"]["get"]
+	if get == nil || get.OperationID != "getUser" || get.Summary != "Get a user" || !reflect.DeepEqual(get.Tags, []string{"users"}) {
+		t.Fatalf("unexpected operation: %+v", get)
+	}
+	if p := get.Parameters[0]; p.Name != "id" || p.In != "path" || !p.Required || p.Schema.Pattern != "^[\\d]+$" {
+		t.Errorf("unexpected parameter: %+v", p)
+	}
+	if get.Responses["404"].Description != "Not Found" || get.Responses["200"].Content["application/json"].Schema.Ref != "#/components/schemas/openAPIUser" {
+		t.Errorf("unexpected responses: %+v", get.Responses)
+	}
+
+	put := doc.Paths["/repos/{owner}/{name}"]["put"]
+	if put == nil || put.Parameters[0].Schema.Pattern != "^\\w+$" || put.Parameters[1].Schema.Pattern != "^[\\w]+$" {
+		t.Fatalf("unexpected operation: %+v", put)
+	}
+
+	if doc.Paths["/users"]["post"].RequestBody == nil {
+		t.Fatal("request body is missing")
+	}
+
+	user := doc.Components.Schemas["openAPIUser"]
+	if !reflect.DeepEqual(user.Required, []string{"created_at", "id", "name"}) {
+		t.Errorf("unexpected required fields: %v", user.Required)
+	}
+	if user.Properties["friends"].Items.Ref != "#/components/schemas/openAPIUser" || user.Properties["created_at"].Format != "date-time" {
+		t.Errorf("unexpected properties: %+v", user.Properties)
+	}
+	if _, ok := user.Properties["password"]; ok {
+		t.Error("unexported field is documented")
+	}
+
+	data, err := doc.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded["openapi"] != "3.1.0" {
+		t.Errorf("unexpected json document: %v %s", err, data)
+	}
+}
+
+// Test OpenAPIDocument.YAML
+func TestOpenAPIDocument_YAML(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {}).Tags("users", "<public>")
+
+	data, err := router.OpenAPI(OpenAPIInfo{Title: "users", Version: "1.0.0"}).YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `info:
+  title: "users"
+  version: "1.0.0"
+openapi: "3.1.0"
+paths:
+  /users/{id}:
+    get:
+      parameters:
+      - in: "path"
+        name: "id"
+        required: true
+        schema:
+          pattern: "^[\\d]+$"
+          type: "string"
+      responses:
+        "200":
+          description: "OK"
+      tags:
+      - "users"
+      - "<public>"
+`
+	if string(data) != expected {
+		t.Errorf("unexpected yaml document:\n%s", data)
+	}
+	if strings.Contains(string(data), "\t") {
+		t.Error("yaml document contains tabs")
+	}
+}
+
+// Cookie shares its name with http.Cookie
+type Cookie struct {
+	Flavor string `json:"flavor"`
+}
+
+// Test component names of types sharing a name are qualified by package
+func TestRouter_OpenAPIComponentCollision(t *testing.T) {
+	router := New()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	router.GET("/a", handler).Response(http.StatusOK, Cookie{})
+	router.GET("/b", handler).Response(http.StatusOK, http.Cookie{})
+	router.GET("/c", handler).Response(http.StatusOK, []Cookie{})
+
+	doc := router.OpenAPI(OpenAPIInfo{Title: "cookies", Version: "1.0.0"})
+	local, std := doc.Components.Schemas["Cookie"], doc.Components.Schemas["http.Cookie"]
+	if local == nil || std == nil || len(doc.Components.Schemas) != 2 {
+		t.Fatalf("unexpected components: %v", doc.Components.Schemas)
+	}
+	if local.Properties["flavor"] == nil || std.Properties["Name"] == nil {
+		t.Errorf("components share a schema: %+v %+v", local, std)
+	}
+	refs := []string{
+		doc.Paths["/a"]["get"].Responses["200"].Content["application/json"].Schema.Ref,
+		doc.Paths["/b"]["get"].Responses["200"].Content["application/json"].Schema.Ref,
+		doc.Paths["/c"]["get"].Responses["200"].Content["application/json"].Schema.Items.Ref,
+	}
+	if !reflect.DeepEqual(refs, []string{"#/components/schemas/Cookie", "#/components/schemas/http.Cookie", "#/components/schemas/Cookie"}) {
+		t.Errorf("unexpected refs: %v", refs)
+	}
+}
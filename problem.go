@@ -0,0 +1,189 @@
+package gorouter
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details documents
+const ProblemContentType = "application/problem+json"
+
+// problemMembers are the members defined by RFC 9457, extensions can't override them
+var problemMembers = map[string]struct{}{
+	"type":     {},
+	"title":    {},
+	"status":   {},
+	"detail":   {},
+	"instance": {},
+}
+
+// Problem is an RFC 9457 problem details document. It implements error so
+// HandlerE handlers can return it as is, Extensions are written as
+// additional members of the document.
+type Problem struct {
+	// Type is a URI reference identifying the problem type, defaults to about:blank
+	Type string
+	// Title is a short summary of the problem type
+	Title string
+	// Status is the http status code
+	Status int
+	// Detail is an explanation specific to this occurrence of the problem
+	Detail string
+	// Instance is a URI reference identifying this occurrence, defaults to the request path
+	Instance string
+	// Extensions records additional members
+	Extensions map[string]interface{}
+}
+
+// NewProblem returns a Problem for `status` titled with its status text
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// With sets the extension member `key` and returns the problem
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// Error implements the error interface
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Title + ": " + p.Detail
+	}
+	return p.Title
+}
+
+// MarshalJSON writes the standard members followed by the extensions
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		if _, ok := problemMembers[key]; !ok {
+			members[key] = value
+		}
+	}
+
+	members["type"] = p.Type
+	if p.Type == "" {
+		members["type"] = "about:blank"
+	}
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON reads the standard members and keeps the others as extensions
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	*p = Problem{}
+	fields := map[string]interface{}{
+		"type":     &p.Type,
+		"title":    &p.Title,
+		"status":   &p.Status,
+		"detail":   &p.Detail,
+		"instance": &p.Instance,
+	}
+	for key, raw := range members {
+		if field, ok := fields[key]; ok {
+			// RFC 9457 要求忽略类型错误的标准成员
+			json.Unmarshal(raw, field)
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		p.With(key, value)
+	}
+	return nil
+}
+
+// WriteProblem writes `p` as application/problem+json, an empty Title or
+// Instance is filled from the status and the request path and the id set by
+// RequestID is added as the `request_id` extension
+func WriteProblem(w http.ResponseWriter, r *http.Request, p *Problem) {
+	problem := *p
+	if r != nil {
+		if id := requestID(w, r); id != "" && problem.Extensions["request_id"] == nil {
+			extensions := make(map[string]interface{}, len(problem.Extensions)+1)
+			for key, value := range problem.Extensions {
+				extensions[key] = value
+			}
+			extensions["request_id"] = id
+			problem.Extensions = extensions
+		}
+	}
+	if !validStatus(problem.Status) {
+		problem.Status = http.StatusInternalServerError
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if problem.Instance == "" && r != nil {
+		problem.Instance = r.URL.Path
+	}
+
+	data, err := json.Marshal(&problem)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	w.Write(data)
+}
+
+// ProblemFromError returns `err` as a Problem, an *HTTPError keeps its status
+// and message, with its code as the `code` extension, and ValidationErrors
+// are listed in the `errors` extension. A *Problem without a valid status is
+// returned as a copy with the status 500.
+func ProblemFromError(err error) *Problem {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		if !validStatus(problem.Status) {
+			withStatus := *problem
+			withStatus.Status = http.StatusInternalServerError
+			return &withStatus
+		}
+		return problem
+	}
+
+	httpErr := ErrorStatus(err)
+	detail := httpErr.Message
+	if detail == http.StatusText(httpErr.Status) {
+		detail = ""
+	}
+	problem = NewProblem(httpErr.Status, detail)
+	if httpErr.Code != "" {
+		problem.With("code", httpErr.Code)
+	}
+	if errs, ok := asValidationErrors(err); ok {
+		problem.With("errors", []FieldError(errs))
+	}
+	return problem
+}
@@ -0,0 +1,131 @@
+package gorouter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func decodeProblem(t *testing.T, rr *httptest.ResponseRecorder) *Problem {
+	t.Helper()
+	if ct := rr.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Fatalf("handler returned wrong content type: got %v want %v", ct, ProblemContentType)
+	}
+	var problem Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Status != rr.Code {
+		t.Errorf("problem status %v differs from response status %v", problem.Status, rr.Code)
+	}
+	return &problem
+}
+
+// Test ProblemDetails for router errors
+func TestRouter_ProblemDetails(t *testing.T) {
+	router := New()
+	router.ProblemDetails = true
+	router.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, expected)
+	})
+	router.PUT("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if problem := decodeProblem(t, rr); rr.Code != http.StatusNotFound || problem.Title != "Not Found" || problem.Instance != "/missing" || problem.Type != "about:blank" {
+		t.Errorf("unexpected not found problem: %v %+v", rr.Code, problem)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/users/1", nil))
+	decodeProblem(t, rr)
+	if rr.Code != http.StatusMethodNotAllowed || rr.Header().Get("Allow") != "GET, PUT" {
+		t.Errorf("unexpected method not allowed response: %v %v", rr.Code, rr.Header().Get("Allow"))
+	}
+
+	// HEAD 无法注册, 不返回 405
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodHead, "/aaa", nil))
+	if rr.Code != http.StatusNotFound || rr.Header().Get("Allow") != "" {
+		t.Errorf("HEAD returned %v %v", rr.Code, rr.Header().Get("Allow"))
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/users/1", nil))
+	decodeProblem(t, rr)
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusInternalServerError)
+	}
+}
+
+// Test problems returned by handlers
+func TestRouter_ProblemDetailsHandleE(t *testing.T) {
+	router := New()
+	router.ProblemDetails = true
+
+	router.POSTE("/orders", func(w http.ResponseWriter, r *http.Request) error {
+		return NewProblem(http.StatusConflict, "order already exists").With("order", "42").With("status", 1)
+	})
+	router.POSTE("/payments", func(w http.ResponseWriter, r *http.Request) error {
+		return &HTTPError{Status: http.StatusPaymentRequired, Code: "insufficient_funds", Message: "balance too low"}
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	problem := decodeProblem(t, rr)
+	if rr.Code != http.StatusConflict || problem.Detail != "order already exists" || problem.Extensions["order"] != "42" {
+		t.Errorf("unexpected problem: %v %+v", rr.Code, problem)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/payments", nil))
+	problem = decodeProblem(t, rr)
+	if rr.Code != http.StatusPaymentRequired || problem.Detail != "balance too low" || problem.Extensions["code"] != "insufficient_funds" {
+		t.Errorf("unexpected problem: %v %+v", rr.Code, problem)
+	}
+
+	// 非法状态码按 500 返回
+	for _, status := range []int{42, 1000} {
+		invalid := &Problem{Status: status, Detail: "bad status"}
+		router.PUTE("/invalid", func(w http.ResponseWriter, r *http.Request) error {
+			return invalid
+		})
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/invalid", nil))
+		problem = decodeProblem(t, rr)
+		if rr.Code != http.StatusInternalServerError || problem.Status != http.StatusInternalServerError || invalid.Status != status {
+			t.Errorf("status %v returned %v %+v", status, rr.Code, problem)
+		}
+	}
+}
+
+// Test method not allowed without ProblemDetails
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	router := New()
+	router.GET("/aaa", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, expected)
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/aaa", nil))
+	if rr.Code != http.StatusMethodNotAllowed || rr.Header().Get("Allow") != http.MethodGet {
+		t.Errorf("unexpected method not allowed response: %v %v", rr.Code, rr.Header().Get("Allow"))
+	}
+
+	router.MethodNotAllowedFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/aaa", nil))
+	if rr.Code != http.StatusTeapot {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusTeapot)
+	}
+}
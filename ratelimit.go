@@ -0,0 +1,189 @@
+package gorouter
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// RateLimitResult is the outcome of taking a token from a bucket
+	RateLimitResult struct {
+		// Allowed reports whether a token was taken
+		Allowed bool
+		// Limit is the capacity of the bucket
+		Limit int
+		// Remaining is the number of tokens left
+		Remaining int
+		// Reset is the time until the bucket is full again
+		Reset time.Duration
+		// RetryAfter is the time until the next token when the request isn't allowed
+		RetryAfter time.Duration
+	}
+
+	// RateLimitStore keeps the token buckets of RateLimit, implement it to
+	// share the limits between instances, e.g. in Redis
+	RateLimitStore interface {
+		// Take takes a token from the bucket `key` holding `limit` tokens refilled over `window`
+		Take(key string, limit int, window time.Duration) (RateLimitResult, error)
+	}
+
+	// RateLimitConfig configures RateLimit
+	RateLimitConfig struct {
+		// Limit is the number of requests allowed per Window, it is also the burst
+		// size, RateLimit panics when it isn't positive
+		Limit int
+		// Window is the period the Limit is refilled over, defaults to a minute
+		Window time.Duration
+		// Key returns the client key of the request, defaults to RateLimitByIP.
+		// Requests with an empty key aren't limited.
+		Key func(r *http.Request) string
+		// Store keeps the buckets, defaults to a MemoryStore
+		Store RateLimitStore
+	}
+
+	// MemoryStore is an in-memory RateLimitStore for a single instance and tests
+	MemoryStore struct {
+		mu      sync.Mutex
+		buckets map[string]*tokenBucket
+		takes   int
+		// now returns the current time, replaced in tests
+		now func() time.Time
+	}
+
+	// tokenBucket records the tokens of a key at the last take
+	tokenBucket struct {
+		tokens float64
+		last   time.Time
+		full   time.Time
+	}
+)
+
+// RateLimit returns a middleware limiting the requests of every client per
+// route with a token bucket. Attach it to a router or group with Use, or to
+// a route with Route.Use. Responses carry the RateLimit-Limit,
+// RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers, rejected
+// requests get 429 Too Many Requests with Retry-After, written with the
+// router's HandleError. Store errors and the CORS preflights answered by the
+// router let the request through.
+// 限流, 按路由与客户端分别计数
+func RateLimit(config RateLimitConfig) MiddlewareType {
+	if config.Limit <= 0 {
+		panic("gorouter: rate limit must be positive")
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.Key == nil {
+		config.Key = RateLimitByIP
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	policy := strconv.Itoa(config.Limit) + ";w=" + strconv.Itoa(int(math.Ceil(config.Window.Seconds())))
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			client := config.Key(r)
+			if client == "" || isPreflight(r) {
+				next(w, r)
+				return
+			}
+			key := r.Method + " " + client
+			if route, ok := CurrentRoute(r); ok {
+				key = r.Method + " " + route.Pattern + " " + client
+			}
+
+			result, err := config.Store.Take(key, config.Limit, config.Window)
+			if err != nil {
+				next(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+			header.Set("RateLimit-Policy", policy)
+			if result.Allowed {
+				next(w, r)
+				return
+			}
+
+			header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+			handleError(w, r, &HTTPError{Status: http.StatusTooManyRequests})
+		}
+	}
+}
+
+// RateLimitByIP keys requests by the host of RemoteAddr. Put a middleware
+// setting RemoteAddr from trusted proxy headers in front when behind a proxy.
+func RateLimitByIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// RateLimitByHeader keys requests by the value of a header, e.g. an API key
+func RateLimitByHeader(name string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*tokenBucket), now: time.Now}
+}
+
+// Take implements RateLimitStore
+func (s *MemoryStore) Take(key string, limit int, window time.Duration) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	rate := float64(limit) / float64(window)
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit), last: now}
+		s.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(float64(limit), bucket.tokens+float64(now.Sub(bucket.last))*rate)
+	bucket.last = now
+	result := RateLimitResult{Limit: limit}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else if rate > 0 {
+		result.RetryAfter = time.Duration((1 - bucket.tokens) / rate)
+	}
+	result.Remaining = int(bucket.tokens)
+	if rate > 0 {
+		result.Reset = time.Duration((float64(limit) - bucket.tokens) / rate)
+	}
+	bucket.full = now.Add(result.Reset)
+	return result, nil
+}
+
+// sweep drops the buckets that are full again every 1024 takes
+func (s *MemoryStore) sweep(now time.Time) {
+	if s.takes++; s.takes%1024 != 0 {
+		return
+	}
+	for key, bucket := range s.buckets {
+		if !now.Before(bucket.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// ceilSeconds formats a duration as whole seconds rounded up
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,118 @@
+package gorouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Test RateLimit keys buckets by route and client
+func TestRateLimit(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	router := New()
+	router.Use(RateLimit(RateLimitConfig{Limit: 2, Window: time.Minute, Store: store}))
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	router.GET("/users/:id", handler)
+	router.GET("/other", handler)
+
+	serve := func(url string, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	tests := []struct {
+		url, remoteAddr string
+		code            int
+		remaining       string
+	}{
+		{"/users/1", "192.0.2.1:1234", http.StatusOK, "1"},
+		{"/users/2", "192.0.2.1:5678", http.StatusOK, "0"},
+		{"/users/3", "192.0.2.1:1234", http.StatusTooManyRequests, "0"},
+		{"/other", "192.0.2.1:1234", http.StatusOK, "1"},
+		{"/users/1", "192.0.2.2:1234", http.StatusOK, "1"},
+	}
+	for _, test := range tests {
+		rr := serve(test.url, test.remoteAddr)
+		if rr.Code != test.code || rr.Header().Get("RateLimit-Remaining") != test.remaining ||
+			rr.Header().Get("RateLimit-Limit") != "2" || rr.Header().Get("RateLimit-Policy") != "2;w=60" {
+			t.Errorf("%v from %v returned %v %v", test.url, test.remoteAddr, rr.Code, rr.Header())
+		}
+		if test.code == http.StatusTooManyRequests && (rr.Header().Get("Retry-After") != "30" || rr.Header().Get("RateLimit-Reset") != "60") {
+			t.Errorf("rejected request returned Retry-After %v RateLimit-Reset %v", rr.Header().Get("Retry-After"), rr.Header().Get("RateLimit-Reset"))
+		}
+	}
+
+	// 30 秒后补充一个令牌
+	now = now.Add(30 * time.Second)
+	if rr := serve("/users/1", "192.0.2.1:1234"); rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("refilled bucket returned %v %v", rr.Code, rr.Header())
+	}
+}
+
+// Test RateLimit on a route keyed by header, requests without key aren't limited
+func TestRateLimit_Header(t *testing.T) {
+	router := New()
+	router.GET("/api", func(w http.ResponseWriter, r *http.Request) {}).
+		Use(RateLimit(RateLimitConfig{Limit: 1, Key: RateLimitByHeader("X-API-Key")}))
+
+	for i, test := range []struct {
+		key  string
+		code int
+	}{
+		{"a", http.StatusOK},
+		{"a", http.StatusTooManyRequests},
+		{"b", http.StatusOK},
+		{"", http.StatusOK},
+		{"", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api", nil)
+		if test.key != "" {
+			req.Header.Set("X-API-Key", test.key)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != test.code {
+			t.Errorf("request %d with key %q returned %v want %v", i, test.key, rr.Code, test.code)
+		}
+	}
+}
+
+// Test rejections honor ProblemDetails
+func TestRateLimit_ProblemDetails(t *testing.T) {
+	router := New()
+	router.ProblemDetails = true
+	router.GET("/api", func(w http.ResponseWriter, r *http.Request) {}).
+		Use(RateLimit(RateLimitConfig{Limit: 1}))
+
+	var rr *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api", nil))
+	}
+	problem := decodeProblem(t, rr)
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Content-Type") != ProblemContentType ||
+		problem.Status != http.StatusTooManyRequests || rr.Header().Get("Retry-After") == "" {
+		t.Errorf("rejection returned %v %v %+v", rr.Code, rr.Header(), problem)
+	}
+}
+
+// Test RateLimit rejects a limit that would block every request
+func TestRateLimit_InvalidLimit(t *testing.T) {
+	for _, limit := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RateLimit accepted the limit %d", limit)
+				}
+			}()
+			RateLimit(RateLimitConfig{Limit: limit})
+		}()
+	}
+}
@@ -0,0 +1,64 @@
+package gorouter
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// handlerPanic is a panic recovered in the goroutine of a handler running
+// with a deadline, it keeps the stack of that goroutine
+type handlerPanic struct {
+	value interface{}
+	stack []byte
+}
+
+// recoverPanic handles a panic recovered by ServeHTTP. http.ErrAbortHandler
+// is re-panicked so net/http aborts the response silently. Otherwise the
+// PanicHandler runs if set, else the panic is logged with its stack and a 500
+// is written. Either way the response gets a 500 if no header was written.
+// 恐慌恢复, 记录堆栈并保证返回 500
+func (r *Router) recoverPanic(w *ResponseWriter, req *http.Request, err interface{}, pattern string) {
+	stack := debug.Stack()
+	if p, ok := err.(*handlerPanic); ok {
+		err, stack = p.value, p.stack
+	}
+	if err == http.ErrAbortHandler {
+		panic(err)
+	}
+
+	if r.PanicHandler != nil {
+		r.PanicHandler(w, req, err)
+	} else {
+		r.logPanic(req, "gorouter: panic recovered", err, stack, pattern, requestID(w, req),
+			slog.Bool("header_written", w.Written()))
+	}
+
+	if w.Written() {
+		return
+	}
+	if r.ProblemDetails {
+		WriteProblem(w, req, NewProblem(http.StatusInternalServerError, ""))
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// logPanic logs a panic with its stack to the router's Logger
+func (r *Router) logPanic(req *http.Request, msg string, err interface{}, stack []byte, pattern string, id string, attrs ...interface{}) {
+	logger := r.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	attrs = append([]interface{}{
+		slog.Any("error", err),
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.String("route", pattern),
+	}, attrs...)
+	attrs = append(attrs, slog.String("stack", string(stack)))
+	if id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	logger.ErrorContext(req.Context(), msg, attrs...)
+}
@@ -0,0 +1,96 @@
+package gorouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test default recovery
+func TestRouter_Recovery(t *testing.T) {
+	var logs bytes.Buffer
+	router := New()
+	router.Logger = slog.New(slog.NewJSONHandler(&logs, nil))
+
+	router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusInternalServerError)
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(logs.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["error"] != "boom" || record["method"] != http.MethodGet || record["path"] != "/users/1" || record["route"] != "/users/:id" {
+		t.Errorf("unexpected log record: %v", record)
+	}
+	if stack, _ := record["stack"].(string); !strings.Contains(stack, "recovery_test.go") {
+		t.Errorf("stack does not contain the panic site: %v", stack)
+	}
+}
+
+// Test recovery after the header was written
+func TestRouter_RecoveryHeaderWritten(t *testing.T) {
+	router := New()
+	router.Logger = slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	router.GET("/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, "partial")
+		panic("boom")
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if rr.Code != http.StatusAccepted || rr.Body.String() != "partial" {
+		t.Errorf("recovery overwrote the response: %v %q", rr.Code, rr.Body.String())
+	}
+}
+
+// Test custom PanicHandler still gets a 500
+func TestRouter_PanicHandlerStatus(t *testing.T) {
+	router := New()
+
+	var recovered interface{}
+	router.PanicHandler = func(w http.ResponseWriter, r *http.Request, err interface{}) {
+		recovered = err
+	}
+	router.GET("/hi", func(w http.ResponseWriter, r *http.Request) {
+		panic("err")
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/hi", nil))
+	if recovered != "err" || rr.Code != http.StatusInternalServerError {
+		t.Errorf("PanicHandler got %v and the response is %v", recovered, rr.Code)
+	}
+}
+
+// Test http.ErrAbortHandler is passed on to net/http
+func TestRouter_RecoveryAbortHandler(t *testing.T) {
+	router := New()
+	router.PanicHandler = func(w http.ResponseWriter, r *http.Request, err interface{}) {
+		t.Error("PanicHandler received http.ErrAbortHandler")
+	}
+	router.GET("/abort", func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Errorf("unexpected panic: %v", err)
+		}
+	}()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	t.Error("http.ErrAbortHandler was swallowed")
+}
@@ -0,0 +1,115 @@
+package gorouter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the default header carrying the request id
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDConfig configures RequestID
+type RequestIDConfig struct {
+	// Header is the request and response header of the id, defaults to RequestIDHeader
+	Header string
+	// Generator returns a new id, defaults to a random UUID
+	Generator func() string
+	// Validate reports whether an incoming id is kept, defaults to 1 to 128
+	// letters, digits and `-_.:` characters
+	Validate func(id string) bool
+}
+
+// requestIDKeyType is the private context key type of the request id
+type requestIDKeyType struct{}
+
+// requestIDKey is the context key RequestID stores the id under
+var requestIDKey = requestIDKeyType{}
+
+// RequestID returns a middleware keeping a valid incoming request id or
+// generating one. The id is stored in the request context, see GetRequestID,
+// and echoed in the response header, which also makes it available to the
+// access log, the panic log and the problem+json responses of the router.
+// 请求 ID
+func RequestID(config ...RequestIDConfig) MiddlewareType {
+	var c RequestIDConfig
+	if len(config) > 0 {
+		c = config[0]
+	}
+	if c.Header == "" {
+		c.Header = RequestIDHeader
+	}
+	if c.Generator == nil {
+		c.Generator = newUUID
+	}
+	if c.Validate == nil {
+		c.Validate = validRequestID
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(c.Header)
+			if !c.Validate(id) {
+				id = c.Generator()
+			}
+			w.Header().Set(c.Header, id)
+			// 记录在 ResponseWriter 上, 供路由记录恐慌与错误时读取
+			walkResponseWriters(w, func(rw *ResponseWriter) bool {
+				rw.requestID = id
+				return true
+			})
+			next(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+		}
+	}
+}
+
+// GetRequestID returns the id stored by RequestID, empty without one
+func GetRequestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey).(string)
+	return id
+}
+
+// requestID returns the id of the request, falling back to the id RequestID
+// recorded on the ResponseWriter for requests whose context was created
+// before RequestID ran, and then to the RequestIDHeader response header
+func requestID(w http.ResponseWriter, r *http.Request) string {
+	if id := GetRequestID(r); id != "" {
+		return id
+	}
+	var id string
+	walkResponseWriters(w, func(rw *ResponseWriter) bool {
+		id = rw.requestID
+		return id == ""
+	})
+	if id != "" {
+		return id
+	}
+	return w.Header().Get(RequestIDHeader)
+}
+
+// validRequestID reports whether id is 1 to 128 letters, digits and `-_.:` characters
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	s := hex.EncodeToString(b[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
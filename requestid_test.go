@@ -0,0 +1,94 @@
+package gorouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Test RequestID keeps valid ids and generates the others
+func TestRequestID(t *testing.T) {
+	router := New()
+	router.Use(RequestID())
+
+	var seen string
+	router.GET("/users", func(w http.ResponseWriter, r *http.Request) {
+		seen = GetRequestID(r)
+	})
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for _, incoming := range []string{"abc-123", "", "bad id", strings.Repeat("a", 129)} {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		if incoming != "" {
+			req.Header.Set(RequestIDHeader, incoming)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		id := rr.Header().Get(RequestIDHeader)
+		if id != seen {
+			t.Errorf("response id %q differs from context id %q", id, seen)
+		}
+		if incoming == "abc-123" && id != incoming || incoming != "abc-123" && !uuid.MatchString(id) {
+			t.Errorf("incoming id %q returned %q", incoming, id)
+		}
+	}
+
+	// 自定义头与生成器
+	router = New()
+	router.Use(RequestID(RequestIDConfig{Header: "X-Trace", Generator: func() string { return "generated" }}))
+	router.GET("/users", func(w http.ResponseWriter, r *http.Request) {})
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if rr.Header().Get("X-Trace") != "generated" {
+		t.Errorf("custom header returned %v", rr.Header())
+	}
+}
+
+// Test the request id reaches the access log, panic log and problem responses
+func TestRequestID_Propagation(t *testing.T) {
+	for _, header := range []string{RequestIDHeader, "X-Correlation-ID"} {
+		var logs, access bytes.Buffer
+		router := New()
+		router.ProblemDetails = true
+		router.Logger = slog.New(slog.NewJSONHandler(&logs, nil))
+		router.Use(RequestID(RequestIDConfig{Header: header}))
+		router.GET("/panic", func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})
+		handler := AccessLog(AccessLogConfig{Format: JSONLogFormat, Output: &access})(router.ServeHTTP)
+
+		req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+		req.Header.Set(header, "req-1")
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+
+		var problem Problem
+		if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+			t.Fatal(err)
+		}
+		if rr.Code != http.StatusInternalServerError || problem.Extensions["request_id"] != "req-1" {
+			t.Errorf("%v: problem returned %v %+v", header, rr.Code, problem)
+		}
+
+		var record map[string]interface{}
+		if err := json.Unmarshal(logs.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		if record["request_id"] != "req-1" {
+			t.Errorf("%v: panic log missing request id: %v", header, record)
+		}
+		record = nil
+		if err := json.Unmarshal(access.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		if record["request_id"] != "req-1" {
+			t.Errorf("%v: access log missing request id: %v", header, record)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package gorouter
+
+import (
+	"net/http"
+	"sort"
+)
+
+type (
+	// Route is returned by the registration methods and configures the
+	// registered route, e.g. router.GET("/users/:id", h).Name("user").
+	// Every change is published atomically to the route table.
+	Route struct {
+		table  *routeTable
+		method string
+		path   string
+	}
+
+	// RouteInfo describes a registered route, as returned by Routes and Walk
+	RouteInfo struct {
+		// Method is the http method of the route
+		Method string `json:"method"`
+		// Pattern is the registered path, e.g. `/users/:id`
+		Pattern string `json:"pattern"`
+		// Name is the route name used by Generate, empty for unnamed routes
+		Name string `json:"name,omitempty"`
+		// Middleware is the number of middleware wrapped around the handler
+		Middleware int `json:"middleware"`
+		// Metadata records the values attached with Route.Meta
+		Metadata map[string]interface{} `json:"metadata,omitempty"`
+		// Consumes records the request media types declared with Route.Consumes
+		Consumes []string `json:"consumes,omitempty"`
+		// Produces records the response media types declared with Route.Produces
+		Produces []string `json:"produces,omitempty"`
+	}
+)
+
+// routeContextKeyType is the private context key type of the matched route
+type routeContextKeyType struct{}
+
+// routeContextKey is the context key ServeHTTP stores the matched route under
+var routeContextKey = routeContextKeyType{}
+
+// matchedRoute records the node matched by ServeHTTP and the request method
+type matchedRoute struct {
+	method string
+	node   *Node
+}
+
+// CurrentRoute returns the route matched for the request, it is set by
+// ServeHTTP before the middleware chain runs so middleware can key logs,
+// metrics or authorization off the pattern instead of the raw path.
+// ok is false for requests that matched no route, e.g. in the not found handler.
+// 返回当前请求匹配的路由
+func CurrentRoute(r *http.Request) (info RouteInfo, ok bool) {
+	route, ok := r.Context().Value(routeContextKey).(matchedRoute)
+	if !ok {
+		return RouteInfo{}, false
+	}
+	return route.node.info(route.method), true
+}
+
+// Name names the route for reverse routing with Generate
+func (rt *Route) Name(routeName string) *Route {
+	return rt.update(func(tree *Tree, node *Node) {
+		if node.name != "" && tree.routes[node.name] == node {
+			delete(tree.routes, node.name)
+		}
+		node.name = routeName
+		if routeName != "" {
+			// 路由名唯一, 之前使用该名字的路由变为匿名
+			if previous, ok := tree.routes[routeName]; ok && previous != node {
+				previous.name = ""
+			}
+			tree.routes[routeName] = node
+		}
+	})
+}
+
+// Meta attaches a metadata value to the route, it is reported by Routes and Walk
+func (rt *Route) Meta(key string, value interface{}) *Route {
+	return rt.update(func(tree *Tree, node *Node) {
+		meta := make(map[string]interface{}, len(node.meta)+1)
+		for k, v := range node.meta {
+			meta[k] = v
+		}
+		meta[key] = value
+		node.meta = meta
+	})
+}
+
+// Use adds middleware to this route only, it runs inside the middleware of
+// the router or group the route was registered on
+func (rt *Route) Use(middleware ...MiddlewareType) *Route {
+	return rt.update(func(tree *Tree, node *Node) {
+		// handle 中越靠前的中间件越靠内层
+		node.middleware = append(append([]MiddlewareType(nil), middleware...), node.middleware...)
+	})
+}
+
+// update applies fn to a private copy of the route's node, its meta map is
+// still shared with published snapshots so fn must replace it rather than mutate it
+func (rt *Route) update(fn func(tree *Tree, node *Node)) *Route {
+	rt.table.update(func(s *routeSnapshot) {
+		if _, ok := s.trees[rt.method]; !ok {
+			return
+		}
+		tree := s.tree(rt.method)
+		if node := tree.node(rt.path); node != nil {
+			fn(tree, node)
+		}
+	})
+	return rt
+}
+
+// Routes returns every registered route sorted by pattern and method
+// 返回所有已注册的路由
+func (r *Router) Routes() []RouteInfo {
+	var routes []RouteInfo
+	for method, tree := range r.table.load().trees {
+		queue := []*Node{tree.root}
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			if node.handle != nil {
+				routes = append(routes, node.info(method))
+			}
+			for _, child := range node.children {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Walk calls fn for every registered route in the order of Routes,
+// it stops at and returns the first error returned by fn
+func (r *Router) Walk(fn func(route RouteInfo) error) error {
+	for _, route := range r.Routes() {
+		if err := fn(route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// info returns the RouteInfo of a node holding a handle
+func (n *Node) info(method string) RouteInfo {
+	info := RouteInfo{
+		Method:     method,
+		Pattern:    "/" + trimPathPrefix(n.path),
+		Name:       n.name,
+		Middleware: len(n.middleware),
+		Consumes:   append([]string(nil), n.consumes...),
+		Produces:   append([]string(nil), n.produces...),
+	}
+	if len(n.meta) > 0 {
+		info.Metadata = make(map[string]interface{}, len(n.meta))
+		for k, v := range n.meta {
+			info.Metadata[k] = v
+		}
+	}
+	return info
+}
@@ -0,0 +1,136 @@
+package gorouter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// Test Routes
+func TestRouter_Routes(t *testing.T) {
+	router := New()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	router.POST("/users", handler)
+	router.GETAndName("/users/:id", handler, "user").Meta("owner", "accounts")
+	router.Use(withLoggint)
+	router.Group("/api").GET("/hi", handler)
+	router.GET("/users", handler)
+	router.GET("/", handler)
+
+	expected := []RouteInfo{
+		{Method: http.MethodGet, Pattern: "/"},
+		{Method: http.MethodGet, Pattern: "/api/hi", Middleware: 1},
+		{Method: http.MethodGet, Pattern: "/users"},
+		{Method: http.MethodPost, Pattern: "/users"},
+		{Method: http.MethodGet, Pattern: "/users/:id", Name: "user", Metadata: map[string]interface{}{"owner": "accounts"}},
+	}
+	expected[0].Middleware, expected[2].Middleware = 1, 1
+
+	if routes := router.Routes(); !reflect.DeepEqual(routes, expected) {
+		t.Errorf("Routes returned unexpected routes: got %+v want %+v", routes, expected)
+	}
+}
+
+// Test duplicated slashes are collapsed and trailing slashes keep routes apart
+func TestRouter_CleanPath(t *testing.T) {
+	router := New()
+	router.GET("/x/", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("x/")) })
+	router.Group("/api/").GET("//y", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("y")) })
+
+	tests := []struct {
+		url  string
+		code int
+		body string
+	}{
+		{"/x/", http.StatusOK, "x/"},
+		{"/x", http.StatusNotFound, "404 page not found\n"},
+		{"/api/y", http.StatusOK, "y"},
+		{"/api/y/", http.StatusOK, "y"},
+	}
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.url, nil))
+		if rr.Code != test.code || rr.Body.String() != test.body {
+			t.Errorf("%v returned %v %q want %v %q", test.url, rr.Code, rr.Body.String(), test.code, test.body)
+		}
+	}
+}
+
+// Test Walk
+func TestRouter_Walk(t *testing.T) {
+	router := New()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	router.GET("/a", handler)
+	router.GET("/b", handler)
+	router.GET("/c", handler)
+
+	var patterns []string
+	errStop := errors.New("stop")
+	err := router.Walk(func(route RouteInfo) error {
+		patterns = append(patterns, route.Pattern)
+		if route.Pattern == "/b" {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop || !reflect.DeepEqual(patterns, []string{"/a", "/b"}) {
+		t.Errorf("Walk visited %v and returned %v", patterns, err)
+	}
+}
+
+// Test Route.Name
+func TestRoute_Name(t *testing.T) {
+	router := New()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	router.GET("/users/:user", handler).Name("user")
+	router.GET("/people/:user", handler).Name("user")
+	router.GET("/unnamed", handler)
+
+	if url, _ := router.Generate(http.MethodGet, "user", map[string]string{"user": "jerrywu"}); url != "/people/jerrywu" {
+		t.Fatal("TestRoute_Name test fail")
+	}
+	for _, route := range router.Routes() {
+		if route.Pattern != "/people/:user" && route.Name != "" {
+			t.Errorf("route %v kept name %v", route.Pattern, route.Name)
+		}
+	}
+}
+
+// Test CurrentRoute is visible to middleware and handlers
+func TestCurrentRoute(t *testing.T) {
+	router := New()
+
+	var fromMiddleware, fromHandler RouteInfo
+	var matched bool
+	router.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fromMiddleware, matched = CurrentRoute(r)
+			next(w, r)
+		}
+	})
+	router.NotFoundFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, matched = CurrentRoute(r)
+	})
+	router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		fromHandler, _ = CurrentRoute(r)
+	}).Name("user").Meta("auth", "admin")
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/7", nil))
+	if !matched || fromMiddleware.Pattern != "/users/:id" || fromMiddleware.Name != "user" ||
+		fromMiddleware.Method != http.MethodGet || fromMiddleware.Metadata["auth"] != "admin" {
+		t.Errorf("middleware saw route %+v %v", fromMiddleware, matched)
+	}
+	if fromHandler.Pattern != "/users/:id" {
+		t.Errorf("handler saw route %+v", fromHandler)
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if matched {
+		t.Errorf("CurrentRoute matched a not found request")
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -38,104 +40,118 @@ type (
 	// 自定义路由 多路复用解析http请求
 	// 记录所有 URL 参数 并执行路由到函数的转发
 	Router struct {
+		// 路由表与错误处理配置, 由 New 创建并被所有分组共享
+		*core
 		// Router 的前缀
 		prefix string
 		// 中间件列表
 		middleware []MiddlewareType
-		// 树结构
-		trees      map[string]*Tree
-		parameters Parameters
+	}
+
+	// core records the state shared by a router and every group created from it,
+	// so handlers configured on any of them apply to all routes
+	core struct {
+		// 路由表, 包含树结构与挂载的 http.Handler
+		table *routeTable
 		// Custom route not found handler
 		notFound http.HandlerFunc
+		// Custom method not allowed handler
+		methodNotAllowed http.HandlerFunc
 		// PanicHandler for handling panic. 恐慌路由
+		// When nil the panic is logged to Logger with its stack trace.
+		// A 500 is written afterwards if the response header is still unwritten.
 		PanicHandler func(w http.ResponseWriter, r *http.Request, err interface{})
-	}
-	// 参数记录 - 记录参数
-	Parameters struct {
-		routeName string
+		// Logger records recovered panics, defaults to slog.Default()
+		Logger *slog.Logger
+		// ErrorHandler writes the errors returned by HandlerE handlers
+		ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+		// ProblemDetails writes the router's errors as RFC 9457 application/problem+json documents
+		ProblemDetails bool
 	}
 )
 
 // New returns a newly initialized Router object that implements the Router
 func New() *Router {
 	return &Router{
-		trees: make(map[string]*Tree),
+		core: &core{
+			table: newRouteTable(),
+		},
 	}
 }
 
 // GET adds the route `path` that matches a GET http method to
 // execute the `handle` http.HandlerFunc.
-func (r *Router) GET(path string, handle http.HandlerFunc) {
-	r.Handle(http.MethodGet, path, handle)
+func (r *Router) GET(path string, handle http.HandlerFunc) *Route {
+	return r.Handle(http.MethodGet, path, handle)
 }
 
 // POST adds the route `path` that matches a POST http method to
 // execute the `handle` http.HandlerFunc.
-func (r *Router) POST(path string, handle http.HandlerFunc) {
-	r.Handle(http.MethodPost, path, handle)
+func (r *Router) POST(path string, handle http.HandlerFunc) *Route {
+	return r.Handle(http.MethodPost, path, handle)
 }
 
 // DELETE adds the route `path` that matches a DELETE http method to
 // execute the `handle` http.HandlerFunc.
-func (r *Router) DELETE(path string, handle http.HandlerFunc) {
-	r.Handle(http.MethodDelete, path, handle)
+func (r *Router) DELETE(path string, handle http.HandlerFunc) *Route {
+	return r.Handle(http.MethodDelete, path, handle)
 }
 
 // PUT adds the route `path` that matches a DELETE http method to
 // execute the `handle` http.HandlerFunc.
-func (r *Router) PUT(path string, handle http.HandlerFunc) {
-	r.Handle(http.MethodPut, path, handle)
+func (r *Router) PUT(path string, handle http.HandlerFunc) *Route {
+	return r.Handle(http.MethodPut, path, handle)
 }
 
 // PATCH adds the route `path` that matches a DELETE http method to
 // execute the `handle` http.HandlerFunc.
-func (r *Router) PATCH(path string, handle http.HandlerFunc) {
-	r.Handle(http.MethodPatch, path, handle)
+func (r *Router) PATCH(path string, handle http.HandlerFunc) *Route {
+	return r.Handle(http.MethodPatch, path, handle)
 }
 
 // GETAndName is short for `GET` and Named routeName
-func (r *Router) GETAndName(path string, handle http.HandlerFunc, routeName string) {
-	r.parameters.routeName = routeName
-	r.GET(path, handle)
+func (r *Router) GETAndName(path string, handle http.HandlerFunc, routeName string) *Route {
+	return r.GET(path, handle).Name(routeName)
 }
 
 // POSTAndName is short for `Post` and Named routeName
-func (r *Router) POSTAndName(path string, handle http.HandlerFunc, routeName string) {
-	r.parameters.routeName = routeName
-	r.POST(path, handle)
+func (r *Router) POSTAndName(path string, handle http.HandlerFunc, routeName string) *Route {
+	return r.POST(path, handle).Name(routeName)
 }
 
 // DELETEAndName is short for `DELETE` and Named routeName
-func (r *Router) DELETEAndName(path string, handle http.HandlerFunc, routeName string) {
-	r.parameters.routeName = routeName
-	r.DELETE(path, handle)
+func (r *Router) DELETEAndName(path string, handle http.HandlerFunc, routeName string) *Route {
+	return r.DELETE(path, handle).Name(routeName)
 }
 
 // PUTAndName is short for `PUT` and Named routeName
-func (r *Router) PUTAndName(path string, handle http.HandlerFunc, routeName string) {
-	r.parameters.routeName = routeName
-	r.PUT(path, handle)
+func (r *Router) PUTAndName(path string, handle http.HandlerFunc, routeName string) *Route {
+	return r.PUT(path, handle).Name(routeName)
 }
 
 // PATCHAndName is short for `PUT` and Named routeName
-func (r *Router) PATCHAndName(path string, handle http.HandlerFunc, routeName string) {
-	r.parameters.routeName = routeName
-	r.PATCH(path, handle)
+func (r *Router) PATCHAndName(path string, handle http.HandlerFunc, routeName string) *Route {
+	return r.PATCH(path, handle).Name(routeName)
 }
 
-// Group define routes groups if there is a path prefix that uses `prefix`
+// Group define routes groups if there is a path prefix that uses `prefix`.
+// A group created from a group joins the prefixes, `Group("/api").Group("/v1")`
+// serves `/api/v1/...`, where earlier versions replaced the parent prefix.
+// Groups share the route table as well as the not found, method not allowed,
+// panic and error configuration of the router. Middleware added to a group
+// with Use only applies to the group and the groups created from it afterwards.
 func (r *Router) Group(prefix string) *Router {
 	return &Router{
-		prefix:     prefix,
-		trees:      r.trees,
-		middleware: r.middleware,
+		core:       r.core,
+		prefix:     r.prefix + "/" + prefix,
+		middleware: append([]MiddlewareType(nil), r.middleware...),
 	}
 }
 
 // Generate returns reverse routing by method, routeName and params
 // 通过method，routeName和params生成返回反向路由
 func (r *Router) Generate(method string, routeName string, params map[string]string) (string, error) {
-	tree, ok := r.trees[method]
+	tree, ok := r.table.load().trees[method]
 	if !ok {
 		return "", ErrNotFoundMethod
 	}
@@ -157,6 +173,11 @@ func (r *Router) Generate(method string, routeName string, params map[string]str
 			continue
 		}
 
+		if string(segment[0]) == "*" {
+			segments = append(segments, strings.TrimPrefix(params[string(segment[1:])], "/"))
+			continue
+		}
+
 		if string(segment[0]) == "{" {
 			segmentLen := len(segment)
 			if string(segment[segmentLen-1]) == "}" {
@@ -186,27 +207,61 @@ func (r *Router) NotFoundFunc(handler http.HandlerFunc) {
 }
 
 // Handle register a new request handler with the given path and method.
-func (r *Router) Handle(method string, path string, handle http.HandlerFunc) {
+// The returned Route configures the route further.
+func (r *Router) Handle(method string, path string, handle http.HandlerFunc) *Route {
 	if _, ok := methods[method]; !ok {
 		panic(fmt.Errorf("invalid method"))
 	}
 
-	// 新增路由的时候 以请求方式获取 树结构
-	tree, ok := r.trees[method]
-	if !ok {
-		tree = NewTree()
-		r.trees[method] = tree
+	// 把前缀添加到 新路由前缀
+	path = r.fullPath(path)
+	// 新增路由的时候 以请求方式获取 树结构的副本, 已存在的路由会被替换
+	r.table.update(func(s *routeSnapshot) {
+		s.tree(method).Add(path, handle, r.middleware...)
+	})
+	return &Route{table: r.table, method: method, path: path}
+}
+
+// Remove unregisters the route with the given method and path, it reports
+// whether the route existed. It is safe to call while serving requests.
+func (r *Router) Remove(method string, path string) bool {
+	path = r.fullPath(path)
+
+	var removed bool
+	r.table.update(func(s *routeSnapshot) {
+		if _, ok := s.trees[method]; ok {
+			removed = s.tree(method).Remove(path)
+		}
+	})
+	return removed
+}
+
+// fullPath joins the group prefix and `path` into a clean absolute pattern,
+// a trailing slash is kept so `/x/` and `/x` stay different routes
+func (r *Router) fullPath(path string) string {
+	full := cleanPath(r.prefix + "/" + path)
+	if strings.HasSuffix(path, "/") {
+		full += "/"
 	}
-	// 判断前缀是否为空 不为空把前缀添加到 新路由前缀
-	if r.prefix != "" {
-		path = r.prefix + "/" + path
+	if full == "" {
+		return "/"
 	}
+	return full
+}
 
-	if routeName := r.parameters.routeName; routeName != "" {
-		tree.parameters.routeName = routeName
+// cleanPath collapses duplicated slashes and removes the trailing one,
+// so `/api//admin/` becomes `/api/admin` and `/` becomes the empty path.
+func cleanPath(path string) string {
+	var segments []string
+	for _, segment := range splitPattern(path) {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
 	}
-
-	tree.Add(path, handle, r.middleware...)
+	if len(segments) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(segments, "/")
 }
 
 // GetParam returns route param stored in http.request.
@@ -242,53 +297,173 @@ func (r *Router) Use(middleware ...MiddlewareType) {
 
 // ServeHTTP makes the router implement the http.Handler interface.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var pattern string
 	requestUrl := req.URL.Path
+	snapshot := r.table.load()
+	rw := NewResponseWriter(w)
+	rw.router = r
+	w = rw
 
 	// goroutine 异常捕获
-	if r.PanicHandler != nil {
-		defer func() {
-			if err := recover(); err != nil {
-				r.PanicHandler(w, req, err)
+	defer func() {
+		if err := recover(); err != nil {
+			r.recoverPanic(rw, req, err, pattern)
+		}
+	}()
+
+	if tree, ok := snapshot.trees[req.Method]; ok {
+		if node, matchParamsMap := r.lookup(tree, requestUrl); node != nil {
+			pattern = "/" + trimPathPrefix(node.path)
+			rw.route = node
+			// 在中间件执行前记录匹配的路由
+			ctx := context.WithValue(req.Context(), routeContextKey, matchedRoute{method: req.Method, node: node})
+			if matchParamsMap != nil {
+				ctx = context.WithValue(ctx, contextKey, matchParamsMap)
+			}
+			req = req.WithContext(ctx)
+			handler := node.handle
+			if len(node.consumes) > 0 || len(node.produces) > 0 {
+				handler = func(w http.ResponseWriter, req *http.Request) {
+					if r.checkContentTypes(w, req, node) {
+						node.handle(w, req)
+					}
+				}
+			}
+			if node.timeout > 0 {
+				handler = r.timeoutHandler(node.timeout, handler)
 			}
-		}()
+			handle(w, req, handler, node.middleware)
+			return
+		}
 	}
 
-	if _, ok := r.trees[req.Method]; !ok {
-		r.HandleNotFound(w, req, r.middleware)
+	if snapshot.serveMount(w, req) {
 		return
 	}
 
-	nodes := r.trees[req.Method].Find(requestUrl, false)
+	// 路径存在但请求方式不匹配, 无法注册的方式如 HEAD 仍返回 404
+	if allowed := r.allowedMethods(snapshot, requestUrl); len(allowed) > 0 && registrable(req.Method) {
+		req = req.WithContext(context.WithValue(req.Context(), allowedMethodsKey, allowed))
+		// OPTIONS 请求自动应答, 经过路由的中间件以便 CORS 处理预检请求
+		if req.Method == http.MethodOptions {
+			// 预检请求经过所请求方法的路由中间件
+			method := allowed[0]
+			if requested := req.Header.Get("Access-Control-Request-Method"); snapshot.trees[requested] != nil {
+				if node, _ := r.lookup(snapshot.trees[requested], requestUrl); node != nil {
+					method = requested
+				}
+			}
+			node, _ := r.lookup(snapshot.trees[method], requestUrl)
+			w.Header().Set("Allow", strings.Join(allowed, ", ")+", "+http.MethodOptions)
+			handle(w, req, func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}, node.middleware)
+			return
+		}
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		r.HandleMethodNotAllowed(w, req, r.middleware)
+		return
+	}
+	r.HandleNotFound(w, req, r.middleware)
+}
+
+// lookup returns the node of tree matching requestUrl, the params map is nil
+// unless the node was matched by its pattern
+func (r *Router) lookup(tree *Tree, requestUrl string) (*Node, paramsMapType) {
+	if !strings.HasPrefix(requestUrl, "/") {
+		return nil, nil
+	}
+
+	nodes := tree.Find(requestUrl, false)
 	if len(nodes) > 0 {
 		node := nodes[0]
 		if node.handle != nil {
 			if node.path == requestUrl {
-				handle(w, req, node.handle, node.middleware)
-				return
+				return node, nil
 			}
 			if node.path == requestUrl[1:] {
-				handle(w, req, node.handle, node.middleware)
-				return
+				return node, nil
 			}
 		}
+		return nil, nil
 	}
 
-	if len(nodes) == 0 {
-		res := strings.Split(requestUrl, "/")
-		prefix := res[1]
-		nodes := r.trees[req.Method].Find(prefix, true)
-		for _, node := range nodes {
-			if handler := node.handle; handler != nil && node.path != requestUrl {
-				if matchParamsMap, ok := r.matchAndParse(requestUrl, node.path); ok {
-					ctx := context.WithValue(req.Context(), contextKey, matchParamsMap)
-					req = req.WithContext(ctx)
-					handle(w, req, handler, node.middleware)
-					return
+	res := strings.Split(requestUrl, "/")
+	prefix := res[1]
+	// Find 按 map 顺序返回候选节点, 取优先级最高的匹配
+	var (
+		matched       *Node
+		matchedParams paramsMapType
+	)
+	for _, node := range tree.Find(prefix, true) {
+		if handler := node.handle; handler != nil && node.path != requestUrl {
+			if matchParamsMap, ok := r.matchAndParse(requestUrl, node.path); ok {
+				if matched == nil || higherPriority(node.path, matched.path) {
+					matched, matchedParams = node, matchParamsMap
 				}
 			}
 		}
 	}
-	r.HandleNotFound(w, req, r.middleware)
+	return matched, matchedParams
+}
+
+// higherPriority reports whether pattern `a` wins over `b` when both match a
+// request: segments are compared from the left, a static segment wins over a
+// param and a param over a catch-all, ties fall back to the pattern order
+func higherPriority(a string, b string) bool {
+	segmentsA, segmentsB := splitPattern(trimPathPrefix(a)), splitPattern(trimPathPrefix(b))
+	for i := 0; i < len(segmentsA) && i < len(segmentsB); i++ {
+		if rankA, rankB := segmentRank(segmentsA[i]), segmentRank(segmentsB[i]); rankA != rankB {
+			return rankA > rankB
+		}
+	}
+	if len(segmentsA) != len(segmentsB) {
+		return len(segmentsA) > len(segmentsB)
+	}
+	return a < b
+}
+
+// segmentRank ranks a pattern segment: 2 for static, 1 for params and 0 for catch-all
+func segmentRank(segment string) int {
+	switch {
+	case strings.HasPrefix(segment, "*"):
+		return 0
+	case strings.HasPrefix(segment, ":"), strings.HasPrefix(segment, "{"):
+		return 1
+	}
+	return 2
+}
+
+// allowedMethods returns the sorted methods having a route matching requestUrl
+func (r *Router) allowedMethods(snapshot *routeSnapshot, requestUrl string) []string {
+	var allowed []string
+	for method, tree := range snapshot.trees {
+		if node, _ := r.lookup(tree, requestUrl); node != nil {
+			allowed = append(allowed, method)
+		}
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+// registrable reports whether routes can be registered for method, OPTIONS
+// requests are answered from the routes of the other methods
+func registrable(method string) bool {
+	_, ok := methods[method]
+	return ok || method == http.MethodOptions
+}
+
+// allowedMethodsKeyType is the private context key type of the allowed methods
+type allowedMethodsKeyType struct{}
+
+// allowedMethodsKey is the context key ServeHTTP stores the allowed methods under
+var allowedMethodsKey = allowedMethodsKeyType{}
+
+// AllowedMethods returns the methods having a route for the request path, it
+// is set by ServeHTTP for the OPTIONS requests it answers and for 405 responses
+func AllowedMethods(r *http.Request) []string {
+	allowed, _ := r.Context().Value(allowedMethodsKey).([]string)
+	return allowed
 }
 
 // HandleNotFound registers a handler when the request route is not found
@@ -297,9 +472,36 @@ func (r *Router) HandleNotFound(w http.ResponseWriter, req *http.Request, middle
 		handle(w, req, r.notFound, middleware)
 		return
 	}
+	if r.ProblemDetails {
+		handle(w, req, func(w http.ResponseWriter, req *http.Request) {
+			WriteProblem(w, req, NewProblem(http.StatusNotFound, ""))
+		}, middleware)
+		return
+	}
 	http.NotFound(w, req)
 }
 
+// MethodNotAllowedFunc registers a handler when the request path exists for other methods,
+// the Allow header is set before the handler runs
+func (r *Router) MethodNotAllowedFunc(handler http.HandlerFunc) {
+	r.methodNotAllowed = handler
+}
+
+// HandleMethodNotAllowed writes the 405 response when the request path exists for other methods
+func (r *Router) HandleMethodNotAllowed(w http.ResponseWriter, req *http.Request, middleware []MiddlewareType) {
+	handler := r.methodNotAllowed
+	if handler == nil {
+		handler = func(w http.ResponseWriter, req *http.Request) {
+			if r.ProblemDetails {
+				WriteProblem(w, req, NewProblem(http.StatusMethodNotAllowed, ""))
+				return
+			}
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	}
+	handle(w, req, handler, middleware)
+}
+
 // handle executes middleware chain 执行中间件
 func handle(w http.ResponseWriter, req *http.Request, handler http.HandlerFunc, middleware []MiddlewareType) {
 	var basehandler = handler
@@ -322,6 +524,7 @@ func (r *Router) matchAndParse(requestUrl string, path string) (matchParams para
 	var (
 		matchName []string
 		pattern   string
+		catchAll  bool
 	)
 
 	b = true
@@ -349,11 +552,17 @@ func (r *Router) matchAndParse(requestUrl string, path string) (matchParams para
 			} else {
 				pattern = pattern + "/" + "(" + defaultPattern + ")"
 			}
+		} else if string(firstChar) == "*" {
+			// catch-all 匹配剩余的全部路径, 只能作为最后一段
+			matchName = append(matchName, str[1:])
+			pattern = pattern + "/" + "(.*)"
+			catchAll = true
+			break
 		} else {
 			pattern = pattern + "/" + str
 		}
 	}
-	if strings.HasSuffix(requestUrl, "/") {
+	if strings.HasSuffix(requestUrl, "/") && !catchAll {
 		pattern = pattern + "/"
 	}
 	re := regexp.MustCompile(pattern)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -577,3 +578,130 @@ func TestRouter_Generate(t *testing.T) {
 		t.Fatal("TestRouter_Generate test fail")
 	}
 }
+
+// Test groups share not found, method not allowed and panic configuration
+func TestRouter_GroupSharesCore(t *testing.T) {
+	router := New()
+	api := router.Group("/api")
+
+	api.NotFoundFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "group not found")
+	})
+	api.MethodNotAllowedFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprint(w, "group method not allowed")
+	})
+	var recovered interface{}
+	api.PanicHandler = func(w http.ResponseWriter, r *http.Request, err interface{}) {
+		recovered = err
+	}
+
+	router.GET("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("root")
+	})
+	api.GET("/hi", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, expected)
+	})
+
+	tests := []struct {
+		method, url string
+		code        int
+		body        string
+	}{
+		{http.MethodGet, "/api/hi", http.StatusOK, expected},
+		{http.MethodGet, "/xxx", http.StatusNotFound, "group not found"},
+		{http.MethodPost, "/api/hi", http.StatusMethodNotAllowed, "group method not allowed"},
+		{http.MethodGet, "/panic", http.StatusInternalServerError, "Internal Server Error\n"},
+	}
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(test.method, test.url, nil))
+		if rr.Code != test.code || rr.Body.String() != test.body {
+			t.Errorf("%v %v returned %v %q, want %v %q", test.method, test.url, rr.Code, rr.Body.String(), test.code, test.body)
+		}
+	}
+	if recovered != "root" {
+		t.Errorf("PanicHandler set on a group was not used: %v", recovered)
+	}
+}
+
+// Test nested groups join their prefixes and keep their middleware apart
+func TestRouter_GroupNested(t *testing.T) {
+	router := New()
+	header := func(value string) MiddlewareType {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Group", value)
+				next(w, r)
+			}
+		}
+	}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}
+
+	api := router.Group("/api")
+	api.Use(header("api"))
+	v1 := api.Group("/v1")
+	v1.Use(header("v1"))
+	v1.GET("/users", handler)
+	api.GET("/users", handler)
+	router.Group("/v1").GET("/users", handler)
+
+	tests := []struct {
+		url    string
+		groups string
+	}{
+		{"/api/v1/users", "v1,api"},
+		{"/api/users", "api"},
+		{"/v1/users", ""},
+	}
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.url, nil))
+		groups := strings.Join(rr.Header().Values("X-Group"), ",")
+		if rr.Code != http.StatusOK || rr.Body.String() != test.url || groups != test.groups {
+			t.Errorf("%v returned %v %q with groups %q want %q", test.url, rr.Code, rr.Body.String(), groups, test.groups)
+		}
+	}
+}
+
+// Test panics in middleware, handlers and the not found handler
+func TestRouter_PanicRecoveryEverywhere(t *testing.T) {
+	router := New()
+
+	var recovered []interface{}
+	router.PanicHandler = func(w http.ResponseWriter, r *http.Request, err interface{}) {
+		recovered = append(recovered, err)
+	}
+
+	router.GET("/handler", func(w http.ResponseWriter, r *http.Request) {
+		panic("handler")
+	})
+	mw := router.Group("/mw")
+	mw.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			panic("middleware")
+		}
+	})
+	mw.GET("/hi", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, expected)
+	})
+	router.NotFoundFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("not found")
+	})
+
+	for _, url := range []string{"/handler", "/mw/hi", "/missing"} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("%v returned %v, want %v", url, rr.Code, http.StatusInternalServerError)
+		}
+	}
+
+	expected := []interface{}{"handler", "middleware", "not found"}
+	if fmt.Sprint(recovered) != fmt.Sprint(expected) {
+		t.Errorf("PanicHandler recovered %v, want %v", recovered, expected)
+	}
+}
@@ -0,0 +1,180 @@
+package gorouter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// filepathKey is the name of the catch-all param registered by ServeFiles
+const filepathKey = "filepath"
+
+// StaticConfig configures the file server registered by ServeFiles
+type StaticConfig struct {
+	// Browse enables directory listings when a directory has no index file
+	Browse bool
+	// Index lists the file names served for a directory request, defaults to index.html
+	Index []string
+	// SPA serves the root index file for every unknown path under the prefix,
+	// so client side routers can handle deep links
+	SPA bool
+}
+
+// ServeFiles serves files from `fsys` below `prefix` using a catch-all route,
+// e.g. ServeFiles("/static", fsys) answers `/static/css/app.css` with the
+// file `css/app.css`. Any fs.FS works, including embed.FS (use fs.Sub to
+// strip the embedded directory). Conditional and range requests are handled
+// by http.ServeContent.
+// 静态文件服务, 支持 embed.FS 与 SPA 回退
+func (r *Router) ServeFiles(prefix string, fsys fs.FS, config ...StaticConfig) {
+	s := &fileServer{fsys: fsys}
+	if len(config) > 0 {
+		s.config = config[0]
+	}
+	if len(s.config.Index) == 0 {
+		s.config.Index = []string{"index.html"}
+	}
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	r.GET(prefix+"/*"+filepathKey, s.ServeHTTP)
+	if prefix != "" {
+		r.GET(prefix, s.ServeHTTP)
+	}
+}
+
+// fileServer serves a fs.FS behind a catch-all route
+type fileServer struct {
+	fsys   fs.FS
+	config StaticConfig
+}
+
+// ServeHTTP makes the fileServer implement the http.Handler interface.
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	name := strings.TrimPrefix(path.Clean("/"+GetParam(req, filepathKey)), "/")
+	if name == "" {
+		name = "."
+	}
+
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		s.serveFallback(w, req, err)
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		s.serveFallback(w, req, err)
+		return
+	}
+
+	if !stat.IsDir() {
+		serveFile(w, req, f, stat)
+		return
+	}
+
+	// 目录需要以 `/` 结尾, 否则相对链接会解析错误
+	if !strings.HasSuffix(req.URL.Path, "/") {
+		redirectToSlash(w, req)
+		return
+	}
+
+	for _, index := range s.config.Index {
+		if s.serveIndex(w, req, path.Join(name, index)) {
+			return
+		}
+	}
+
+	if s.config.Browse {
+		s.serveDir(w, req, name)
+		return
+	}
+	s.serveFallback(w, req, fs.ErrNotExist)
+}
+
+// serveFallback answers a failed lookup, falling back to the root index file in SPA mode
+func (s *fileServer) serveFallback(w http.ResponseWriter, req *http.Request, err error) {
+	if s.config.SPA && errors.Is(err, fs.ErrNotExist) {
+		for _, index := range s.config.Index {
+			if s.serveIndex(w, req, index) {
+				return
+			}
+		}
+	}
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		http.NotFound(w, req)
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// serveIndex serves the regular file `name` and reports whether it exists
+func (s *fileServer) serveIndex(w http.ResponseWriter, req *http.Request, name string) bool {
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil || stat.IsDir() {
+		return false
+	}
+	serveFile(w, req, f, stat)
+	return true
+}
+
+// serveDir writes a minimal HTML listing of the directory `name`
+func (s *fileServer) serveDir(w http.ResponseWriter, req *http.Request, name string) {
+	entries, err := fs.ReadDir(s.fsys, name)
+	if err != nil {
+		s.serveFallback(w, req, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintln(w, "<!doctype html>\n<pre>")
+	for _, entry := range entries {
+		entryName := entry.Name()
+		if entry.IsDir() {
+			entryName += "/"
+		}
+		link := url.URL{Path: entryName}
+		fmt.Fprintf(w, "<a href=\"%s\">%s</a>\n", link.String(), html.EscapeString(entryName))
+	}
+	fmt.Fprintln(w, "</pre>")
+}
+
+// serveFile hands the file to http.ServeContent, which needs an io.ReadSeeker
+func serveFile(w http.ResponseWriter, req *http.Request, f fs.File, stat fs.FileInfo) {
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+	http.ServeContent(w, req, stat.Name(), stat.ModTime(), content)
+}
+
+// redirectToSlash redirects a directory request to its canonical `/` suffixed url
+func redirectToSlash(w http.ResponseWriter, req *http.Request) {
+	target := path.Base(req.URL.Path) + "/"
+	if req.URL.RawQuery != "" {
+		target += "?" + req.URL.RawQuery
+	}
+	http.Redirect(w, req, target, http.StatusMovedPermanently)
+}
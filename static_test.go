@@ -0,0 +1,139 @@
+package gorouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var staticFS = fstest.MapFS{
+	"index.html":      {Data: []byte("<h1>index</h1>"), ModTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+	"css/app.css":     {Data: []byte("body{}")},
+	"docs/readme.txt": {Data: []byte("0123456789")},
+}
+
+func serveStatic(t *testing.T, router *Router, url string, header http.Header) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// Test ServeFiles
+func TestRouter_ServeFiles(t *testing.T) {
+	router := New()
+	router.ServeFiles("/static", staticFS)
+
+	rr := serveStatic(t, router, "/static/css/app.css", nil)
+	if rr.Body.String() != "body{}" {
+		t.Errorf(errorFormat, rr.Body.String(), "body{}")
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/css") {
+		t.Errorf("handler returned wrong content type: got %v", ct)
+	}
+
+	rr = serveStatic(t, router, "/static/", nil)
+	if rr.Body.String() != "<h1>index</h1>" {
+		t.Errorf(errorFormat, rr.Body.String(), "<h1>index</h1>")
+	}
+
+	rr = serveStatic(t, router, "/static", nil)
+	if rr.Code != http.StatusMovedPermanently || rr.Header().Get("Location") != "/static/" {
+		t.Errorf("handler returned wrong redirect: got %v %v", rr.Code, rr.Header().Get("Location"))
+	}
+
+	rr = serveStatic(t, router, "/static/docs/", nil)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+
+	rr = serveStatic(t, router, "/static/../router.go", nil)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+// Test ServeFiles conditional and range requests
+func TestRouter_ServeFilesContent(t *testing.T) {
+	router := New()
+	router.ServeFiles("/static", staticFS)
+
+	rr := serveStatic(t, router, "/static/docs/readme.txt", http.Header{"Range": {"bytes=2-4"}})
+	if rr.Code != http.StatusPartialContent || rr.Body.String() != "234" {
+		t.Errorf("handler returned wrong range: got %v %v", rr.Code, rr.Body.String())
+	}
+
+	rr = serveStatic(t, router, "/static/index.html", http.Header{"If-Modified-Since": {"Wed, 01 Jan 2020 00:00:00 GMT"}})
+	if rr.Code != http.StatusNotModified {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotModified)
+	}
+}
+
+// Test ServeFiles directory listing and SPA fallback
+func TestRouter_ServeFilesConfig(t *testing.T) {
+	router := New()
+	router.ServeFiles("/files", staticFS, StaticConfig{Browse: true})
+	router.ServeFiles("/app", staticFS, StaticConfig{SPA: true})
+
+	rr := serveStatic(t, router, "/files/docs/", nil)
+	if !strings.Contains(rr.Body.String(), `<a href="readme.txt">readme.txt</a>`) {
+		t.Errorf("handler returned unexpected listing: %v", rr.Body.String())
+	}
+
+	rr = serveStatic(t, router, "/app/users/1", nil)
+	if rr.Body.String() != "<h1>index</h1>" {
+		t.Errorf(errorFormat, rr.Body.String(), "<h1>index</h1>")
+	}
+
+	rr = serveStatic(t, router, "/app/css/app.css", nil)
+	if rr.Body.String() != "body{}" {
+		t.Errorf(errorFormat, rr.Body.String(), "body{}")
+	}
+}
+
+// Test static segments win over params and params over catch-all routes
+func TestRouter_CatchAllPriority(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"/files/a", "param a"},
+		{"/files/readme", "static"},
+		{"/files/a/b", "catch-all a/b"},
+		{"/users/7", "user 7"},
+		{"/css/app.css", "body{}"},
+	}
+	for i := 0; i < 50; i++ {
+		router := New()
+		router.GET("/files/:name", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("param " + GetParam(r, "name")))
+		})
+		router.GET("/files/*path", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("catch-all " + GetParam(r, "path")))
+		})
+		router.GET("/files/readme", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("static"))
+		})
+		router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("user " + GetParam(r, "id")))
+		})
+		router.ServeFiles("/", staticFS)
+
+		for _, test := range tests {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.url, nil))
+			if rr.Body.String() != test.want {
+				t.Fatalf("run %d: %v returned %q want %q", i, test.url, rr.Body.String(), test.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package gorouter
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// routeTable is the copy-on-write route table shared by a router and its groups.
+	// ServeHTTP loads an immutable snapshot without locking, writers clone the
+	// parts they change under mu and atomically publish a new snapshot.
+	// 写时复制的路由表, 允许在处理请求的同时注册和删除路由
+	routeTable struct {
+		mu    sync.Mutex
+		value atomic.Value // *routeSnapshot
+	}
+
+	// routeSnapshot is one published version of the route table, it must not be modified
+	routeSnapshot struct {
+		// trees records a Tree per http method
+		trees map[string]*Tree
+		// mounts records mounted handlers by prefix
+		mounts map[string]http.Handler
+		// owned records trees already cloned by the running update
+		owned map[string]bool
+	}
+)
+
+// newRouteTable returns a routeTable holding an empty snapshot
+func newRouteTable() *routeTable {
+	t := &routeTable{}
+	t.value.Store(&routeSnapshot{
+		trees:  make(map[string]*Tree),
+		mounts: make(map[string]http.Handler),
+	})
+	return t
+}
+
+// load returns the current snapshot, it is safe for concurrent use
+func (t *routeTable) load() *routeSnapshot {
+	return t.value.Load().(*routeSnapshot)
+}
+
+// update calls fn with a private copy of the current snapshot and publishes it.
+// Trees must be obtained through routeSnapshot.tree before they are modified.
+func (t *routeTable) update(fn func(s *routeSnapshot)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	old := t.load()
+	s := &routeSnapshot{
+		trees:  make(map[string]*Tree, len(old.trees)),
+		mounts: make(map[string]http.Handler, len(old.mounts)),
+		owned:  make(map[string]bool),
+	}
+	for method, tree := range old.trees {
+		s.trees[method] = tree
+	}
+	for prefix, handler := range old.mounts {
+		s.mounts[prefix] = handler
+	}
+
+	fn(s)
+
+	s.owned = nil
+	t.value.Store(s)
+}
+
+// tree returns a copy of the method's tree that may be modified by the running
+// update, creating the tree if the method has none yet.
+func (s *routeSnapshot) tree(method string) *Tree {
+	if s.owned[method] {
+		return s.trees[method]
+	}
+
+	tree, ok := s.trees[method]
+	if ok {
+		tree = tree.clone()
+	} else {
+		tree = NewTree()
+	}
+	s.trees[method] = tree
+	s.owned[method] = true
+	return tree
+}
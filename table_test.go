@@ -0,0 +1,137 @@
+package gorouter
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test Remove
+func TestRouter_Remove(t *testing.T) {
+	router := New()
+
+	router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, expected)
+	})
+	router.GET("/users/:id/events", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, expected)
+	})
+
+	if !router.Remove(http.MethodGet, "/users/:id") {
+		t.Fatal("TestRouter_Remove test fail")
+	}
+	if router.Remove(http.MethodGet, "/users/:id") || router.Remove(http.MethodPost, "/users/:id") {
+		t.Fatal("TestRouter_Remove test fail")
+	}
+
+	for url, code := range map[string]int{"/users/1": http.StatusNotFound, "/users/1/events": http.StatusOK} {
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		router.ServeHTTP(rr, req)
+		if rr.Code != code {
+			t.Errorf("handler returned wrong status code for %v: got %v want %v", url, rr.Code, code)
+		}
+	}
+
+	router.Remove(http.MethodGet, "/users/:id/events")
+	if tree := router.table.load().trees[http.MethodGet]; len(tree.root.children) != 0 {
+		t.Fatal("TestRouter_Remove did not prune empty nodes")
+	}
+}
+
+// Test replacing a route
+func TestRouter_Replace(t *testing.T) {
+	router := New()
+
+	router.GET("/hi", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "old")
+	})
+	router.GET("/hi", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, expected)
+	})
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/hi", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(rr, req)
+	if rr.Body.String() != expected {
+		t.Errorf(errorFormat, rr.Body.String(), expected)
+	}
+
+	// 替换的路由不保留旧路由的配置
+	router.POST("/x", func(w http.ResponseWriter, r *http.Request) {}).
+		Name("x").Meta("owner", "old").Timeout(time.Millisecond).Consumes("application/xml").Produces("application/xml")
+	router.POST("/x", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		fmt.Fprint(w, expected)
+	})
+	req = httptest.NewRequest(http.MethodPost, "/x", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Body.String() != expected {
+		t.Errorf("replaced route returned %v %q", rr.Code, rr.Body.String())
+	}
+	for _, route := range router.Routes() {
+		if route.Pattern == "/x" && (route.Name != "" || len(route.Metadata) > 0 || len(route.Consumes) > 0 || len(route.Produces) > 0) {
+			t.Errorf("replaced route kept its configuration: %+v", route)
+		}
+	}
+	if _, err := router.Generate(http.MethodPost, "x", nil); err != ErrNotFoundRouter {
+		t.Errorf("replaced route kept its name: %v", err)
+	}
+}
+
+// Test registering and removing routes while serving, run with `go test -race`
+func TestRouter_ConcurrentRegistration(t *testing.T) {
+	router := New()
+	router.GET("/hi", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, expected)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				path := "/dynamic/" + strconv.Itoa(i) + "/" + strconv.Itoa(j)
+				router.GET(path, func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, path)
+				})
+				router.Group("/api").GET(path, func(w http.ResponseWriter, r *http.Request) {})
+				router.Remove(http.MethodGet, path)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				rr := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, "/hi", nil)
+				router.ServeHTTP(rr, req)
+				if rr.Body.String() != expected {
+					t.Errorf(errorFormat, rr.Body.String(), expected)
+				}
+
+				rr = httptest.NewRecorder()
+				req = httptest.NewRequest(http.MethodGet, "/dynamic/0/"+strconv.Itoa(j), nil)
+				router.ServeHTTP(rr, req)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, ok := router.table.load().trees[http.MethodGet].root.children["dynamic"]; ok {
+		t.Fatal("TestRouter_ConcurrentRegistration left removed routes behind")
+	}
+}
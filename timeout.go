@@ -0,0 +1,153 @@
+package gorouter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Timeout sets a deadline on the request context of the route's handler.
+// The handler writes into a buffer, when it misses the deadline the router
+// answers 503 Service Unavailable through HandleError and later writes of
+// the handler fail with http.ErrHandlerTimeout. Handlers pass the context to
+// downstream calls and read what's left of it with RemainingBudget.
+// Streaming and hijacking aren't supported by the buffered writer. Panics of
+// the handler are recovered with its stack, those after the deadline are logged.
+// 路由超时, 超时后返回 503
+func (rt *Route) Timeout(d time.Duration) *Route {
+	return rt.update(func(tree *Tree, node *Node) {
+		node.timeout = d
+	})
+}
+
+// RemainingBudget returns the time left before the deadline of the request
+// context, ok is false when the context has no deadline
+func RemainingBudget(r *http.Request) (remaining time.Duration, ok bool) {
+	deadline, ok := r.Context().Deadline()
+	if !ok {
+		return 0, false
+	}
+	if remaining = time.Until(deadline); remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// timeoutHandler runs handler with the deadline `d`, see Route.Timeout
+func (r *Router) timeoutHandler(d time.Duration, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithTimeout(req.Context(), d)
+		defer cancel()
+		req = req.WithContext(ctx)
+		// 超时后不再读取 w
+		id := requestID(w, req)
+
+		tw := &timeoutWriter{header: make(http.Header)}
+		done := make(chan struct{})
+		panicked := make(chan *handlerPanic, 1)
+		go func() {
+			defer func() {
+				if err := recover(); err != nil {
+					// 在处理函数的协程中记录堆栈
+					p := &handlerPanic{value: err, stack: debug.Stack()}
+					tw.mu.Lock()
+					timedOut := tw.timedOut
+					if !timedOut {
+						panicked <- p
+					}
+					tw.mu.Unlock()
+					if timedOut && err != http.ErrAbortHandler {
+						r.latePanic(req, id, p)
+					}
+				}
+			}()
+			handler(tw, req)
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			// 在当前协程重新抛出, 由 ServeHTTP 恢复
+			panic(p)
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			header := w.Header()
+			for key, values := range tw.header {
+				header[key] = values
+			}
+			if tw.status == 0 {
+				tw.status = http.StatusOK
+			}
+			w.WriteHeader(tw.status)
+			w.Write(tw.buf.Bytes())
+		case <-ctx.Done():
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			select {
+			case p := <-panicked:
+				panic(p)
+			default:
+			}
+			tw.timedOut = true
+			err := ctx.Err()
+			if errors.Is(err, context.DeadlineExceeded) {
+				err = http.ErrHandlerTimeout
+			}
+			r.HandleError(w, req, err)
+		}
+	}
+}
+
+// latePanic logs a panic of a handler that missed its deadline, the response
+// was already answered
+func (r *Router) latePanic(req *http.Request, id string, p *handlerPanic) {
+	route, _ := CurrentRoute(req)
+	r.logPanic(req, "gorouter: panic after timeout", p.value, p.stack, route.Pattern, id)
+}
+
+// timeoutWriter buffers the response of a handler running with a deadline,
+// writes after the deadline fail with http.ErrHandlerTimeout
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+// Header returns the buffered header
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader records the status code
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut || w.status != 0 {
+		return
+	}
+	// 1xx 信息响应无法缓冲, 忽略
+	if status >= 100 && status < 200 {
+		return
+	}
+	w.status = status
+}
+
+// Write buffers data
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.buf.Write(data)
+}
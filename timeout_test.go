@@ -0,0 +1,139 @@
+package gorouter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test Route.Timeout answers 503 when the handler misses the deadline
+func TestRoute_Timeout(t *testing.T) {
+	router := New()
+
+	release, written := make(chan struct{}), make(chan error, 1)
+	router.GET("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("X-Late", "1")
+		_, err := w.Write([]byte(expected))
+		written <- err
+	}).Timeout(10 * time.Millisecond)
+
+	var budget time.Duration
+	var hasBudget bool
+	router.GET("/fast", func(w http.ResponseWriter, r *http.Request) {
+		budget, hasBudget = RemainingBudget(r)
+		w.Header().Set("X-Fast", "1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(expected))
+	}).Timeout(time.Second)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if rr.Code != http.StatusServiceUnavailable || rr.Header().Get("X-Late") != "" {
+		t.Errorf("slow handler returned %v %v", rr.Code, rr.Header())
+	}
+	close(release)
+	if err := <-written; err != http.ErrHandlerTimeout {
+		t.Errorf("late write returned %v want %v", err, http.ErrHandlerTimeout)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if rr.Code != http.StatusCreated || rr.Header().Get("X-Fast") != "1" || rr.Body.String() != expected {
+		t.Errorf("fast handler returned %v %v %q", rr.Code, rr.Header(), rr.Body.String())
+	}
+	if !hasBudget || budget <= 0 || budget > time.Second {
+		t.Errorf("RemainingBudget returned %v %v", budget, hasBudget)
+	}
+
+	// 没有截止时间
+	if _, ok := RemainingBudget(httptest.NewRequest(http.MethodGet, "/", nil)); ok {
+		t.Errorf("RemainingBudget found a deadline")
+	}
+}
+
+// Test timeouts in problem mode, panics and deadline errors of HandlerE handlers
+func TestRoute_TimeoutErrors(t *testing.T) {
+	router := New()
+	router.ProblemDetails = true
+	router.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	router.GET("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}).Timeout(time.Millisecond)
+	router.GET("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}).Timeout(time.Second)
+	router.GETE("/downstream", func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	tests := []struct {
+		url  string
+		code int
+	}{
+		{"/slow", http.StatusServiceUnavailable},
+		{"/panic", http.StatusInternalServerError},
+		{"/downstream", http.StatusGatewayTimeout},
+	}
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.url, nil))
+		if rr.Code != test.code || rr.Header().Get("Content-Type") != ProblemContentType {
+			t.Errorf("%v returned %v %v want %v", test.url, rr.Code, rr.Header().Get("Content-Type"), test.code)
+		}
+	}
+}
+
+// Test panics of handlers with a deadline keep their stack and are logged after the deadline
+func TestRoute_TimeoutPanic(t *testing.T) {
+	logs := make(chan []byte, 2)
+	router := New()
+	router.Logger = slog.New(slog.NewJSONHandler(logWriter(logs), nil))
+
+	release := make(chan struct{})
+	router.GET("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}).Timeout(time.Second)
+	router.GET("/late/:id", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		panic("late")
+	}).Timeout(time.Millisecond)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	var record map[string]interface{}
+	if err := json.Unmarshal(<-logs, &record); err != nil {
+		t.Fatal(err)
+	}
+	if stack, _ := record["stack"].(string); rr.Code != http.StatusInternalServerError || !strings.Contains(stack, "TestRoute_TimeoutPanic.func1") {
+		t.Errorf("panic returned %v, stack misses the handler: %v", rr.Code, stack)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/late/7", nil))
+	close(release)
+	record = nil
+	if err := json.Unmarshal(<-logs, &record); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusServiceUnavailable || record["error"] != "late" || record["route"] != "/late/:id" || record["msg"] != "gorouter: panic after timeout" {
+		t.Errorf("late panic returned %v and logged %v", rr.Code, record)
+	}
+}
+
+// logWriter sends every write to a channel
+type logWriter chan []byte
+
+func (w logWriter) Write(data []byte) (int, error) {
+	w <- append([]byte(nil), data...)
+	return len(data), nil
+}
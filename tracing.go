@@ -0,0 +1,173 @@
+package gorouter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// TraceParentHeader is the W3C trace context header
+const TraceParentHeader = "traceparent"
+
+// ErrInvalidTraceParent is returned by ParseTraceParent for malformed headers
+var ErrInvalidTraceParent = errors.New("invalid traceparent header")
+
+type (
+	// SpanContext identifies a span as propagated by the W3C traceparent header
+	SpanContext struct {
+		TraceID [16]byte
+		SpanID  [8]byte
+		// Flags are the trace flags, 0x01 means sampled
+		Flags byte
+	}
+
+	// Span is a request span, adapters forward it to OpenTelemetry or any tracer
+	Span interface {
+		// SpanContext returns the identifiers of the span
+		SpanContext() SpanContext
+		// SetName renames the span once the route is known
+		SetName(name string)
+		// SetAttribute records an attribute on the span
+		SetAttribute(key string, value interface{})
+		// End finishes the span
+		End()
+	}
+
+	// Tracer starts spans, `parent` is the remote span context of the request
+	// and is invalid when the request has no traceparent header. The returned
+	// context carries the span for the tracer, like OpenTelemetry's Tracer.Start,
+	// and is passed on to the handler.
+	Tracer interface {
+		Start(ctx context.Context, name string, parent SpanContext) (context.Context, Span)
+	}
+)
+
+// spanContextKeyType is the private context key type of the request span
+type spanContextKeyType struct{}
+
+// spanContextKey is the context key Trace stores the span under
+var spanContextKey = spanContextKeyType{}
+
+// IsValid reports whether the trace and span ids are both non zero
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// IsSampled reports whether the sampled flag is set
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&0x01 == 0x01
+}
+
+// TraceParent returns the span context as a version 00 traceparent header value
+func (sc SpanContext) TraceParent() string {
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" + hex.EncodeToString(sc.SpanID[:]) + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// ParseTraceParent parses a W3C traceparent header value. Versions after 00
+// are parsed by their first four fields as the specification requires.
+func ParseTraceParent(header string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, ErrInvalidTraceParent
+	}
+	version, err := hex.DecodeString(parts[0])
+	if err != nil || version[0] == 0xff || version[0] == 0 && len(parts) != 4 {
+		return sc, ErrInvalidTraceParent
+	}
+	if strings.ToLower(header) != header {
+		return sc, ErrInvalidTraceParent
+	}
+
+	var flags []byte
+	if _, err = hex.Decode(sc.TraceID[:], []byte(parts[1])); err == nil {
+		if _, err = hex.Decode(sc.SpanID[:], []byte(parts[2])); err == nil {
+			flags, err = hex.DecodeString(parts[3])
+		}
+	}
+	if err != nil || !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+	sc.Flags = flags[0]
+	return sc, nil
+}
+
+// NewSpanContext returns a span context with a random span id in the trace of
+// `parent`, a new trace is started when `parent` is invalid. Tracer
+// implementations use it to identify their spans.
+func NewSpanContext(parent SpanContext) SpanContext {
+	sc := SpanContext{TraceID: parent.TraceID, Flags: parent.Flags}
+	if !parent.IsValid() {
+		rand.Read(sc.TraceID[:])
+		sc.Flags = 0x01
+	}
+	rand.Read(sc.SpanID[:])
+	return sc
+}
+
+// SpanFromContext returns the span started by Trace for the request context
+func SpanFromContext(ctx context.Context) (Span, bool) {
+	span, ok := ctx.Value(spanContextKey).(Span)
+	return span, ok
+}
+
+// InjectTraceParent sets the traceparent header of an outgoing request to the
+// span of ctx, it reports whether a span was found
+func InjectTraceParent(ctx context.Context, header http.Header) bool {
+	span, ok := SpanFromContext(ctx)
+	if !ok || !span.SpanContext().IsValid() {
+		return false
+	}
+	header.Set(TraceParentHeader, span.SpanContext().TraceParent())
+	return true
+}
+
+// Trace returns a middleware starting a span per request named
+// `METHOD /route/:pattern` that continues the trace of the traceparent header.
+// The span records the method, route, params and status as attributes and is
+// available to handlers with SpanFromContext. Register it with Router.Use to
+// record params, wrapping the router names unmatched requests by method only.
+// 链路追踪, 兼容 OpenTelemetry
+func Trace(tracer Tracer) MiddlewareType {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			parent, _ := ParseTraceParent(r.Header.Get(TraceParentHeader))
+			route, matched := CurrentRoute(r)
+			name := r.Method
+			if matched {
+				name += " " + route.Pattern
+			}
+
+			ctx, span := tracer.Start(r.Context(), name, parent)
+			defer span.End()
+			span.SetAttribute("http.request.method", r.Method)
+			span.SetAttribute("url.path", r.URL.Path)
+			for key, value := range GetAllParams(r) {
+				span.SetAttribute("http.route.param."+key, value)
+			}
+
+			rw := NewResponseWriter(w)
+			next(rw, r.WithContext(context.WithValue(ctx, spanContextKey, span)))
+
+			// 在路由外部注册时, 请求结束后才知道匹配的路由
+			if node := matchedNode(rw); !matched && node != nil {
+				route.Pattern, matched = "/"+trimPathPrefix(node.path), true
+				span.SetName(r.Method + " " + route.Pattern)
+			}
+			if matched {
+				span.SetAttribute("http.route", route.Pattern)
+			}
+			status := rw.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			span.SetAttribute("http.response.status_code", status)
+			if status >= http.StatusInternalServerError {
+				span.SetAttribute("error", true)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package gorouter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// memorySpan is a span recorded by memoryTracer
+type memorySpan struct {
+	name       string
+	parent     SpanContext
+	spanCtx    SpanContext
+	attributes map[string]interface{}
+	ended      bool
+}
+
+func (s *memorySpan) SpanContext() SpanContext { return s.spanCtx }
+func (s *memorySpan) SetName(name string)      { s.name = name }
+func (s *memorySpan) End()                     { s.ended = true }
+func (s *memorySpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+// memoryTracer exports spans in memory
+type memoryTracer struct {
+	mu    sync.Mutex
+	spans []*memorySpan
+}
+
+// memorySpanKey is the context key memoryTracer stores its span under
+type memorySpanKey struct{}
+
+func (t *memoryTracer) Start(ctx context.Context, name string, parent SpanContext) (context.Context, Span) {
+	span := &memorySpan{name: name, parent: parent, spanCtx: NewSpanContext(parent), attributes: make(map[string]interface{})}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, memorySpanKey{}, span), span
+}
+
+// Test ParseTraceParent and TraceParent
+func TestParseTraceParent(t *testing.T) {
+	header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceParent(header)
+	if err != nil || !sc.IsValid() || !sc.IsSampled() || sc.TraceParent() != header {
+		t.Errorf("ParseTraceParent returned %v %v", sc.TraceParent(), err)
+	}
+	if _, err := ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future"); err != nil {
+		t.Errorf("ParseTraceParent rejected a future version: %v", err)
+	}
+
+	for _, header := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceParent(header); err != ErrInvalidTraceParent {
+			t.Errorf("ParseTraceParent(%q) returned %v", header, err)
+		}
+	}
+}
+
+// Test Trace spans registered with Use and wrapping the router
+func TestTrace(t *testing.T) {
+	tracer := &memoryTracer{}
+	router := New()
+	router.Use(Trace(tracer))
+
+	var injected string
+	var tracerSpan interface{}
+	router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		tracerSpan = r.Context().Value(memorySpanKey{})
+		header := http.Header{}
+		if InjectTraceParent(r.Context(), header) {
+			injected = header.Get(TraceParentHeader)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	req.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Trace started %d spans", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "GET /users/:id" || !span.ended || span.parent.TraceParent() != req.Header.Get(TraceParentHeader) {
+		t.Errorf("unexpected span %+v", span)
+	}
+	if tracerSpan != span {
+		t.Errorf("handler didn't get the context returned by the tracer")
+	}
+	if span.spanCtx.TraceID != span.parent.TraceID || injected != span.spanCtx.TraceParent() {
+		t.Errorf("span %v didn't continue the trace, injected %v", span.spanCtx.TraceParent(), injected)
+	}
+	for key, value := range map[string]interface{}{
+		"http.request.method":       http.MethodGet,
+		"http.route":                "/users/:id",
+		"http.route.param.id":       "7",
+		"http.response.status_code": http.StatusInternalServerError,
+		"error":                     true,
+	} {
+		if span.attributes[key] != value {
+			t.Errorf("span attribute %v is %v want %v", key, span.attributes[key], value)
+		}
+	}
+
+	// 包装路由时请求结束后再命名
+	tracer = &memoryTracer{}
+	router = New()
+	router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {})
+	handler := Trace(tracer)(router.ServeHTTP)
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/7", nil))
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if tracer.spans[0].name != "GET /users/:id" || tracer.spans[1].name != "GET" || tracer.spans[0].parent.IsValid() {
+		t.Errorf("unexpected spans %v %v", tracer.spans[0].name, tracer.spans[1].name)
+	}
+	if tracer.spans[1].attributes["http.response.status_code"] != http.StatusNotFound {
+		t.Errorf("unexpected not found status %v", tracer.spans[1].attributes["http.response.status_code"])
+	}
+}
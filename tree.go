@@ -3,14 +3,14 @@ package gorouter
 import (
 	"net/http"
 	"strings"
+	"time"
 )
 
 type (
 	// Tree records node
 	Tree struct {
-		root       *Node
-		parameters Parameters
-		routes     map[string]*Node
+		root   *Node
+		routes map[string]*Node
 	}
 
 	// Node records any URL params, and executes an end handler.
@@ -27,6 +27,16 @@ type (
 		isPattern bool
 		// middleware records middleware stack
 		middleware []MiddlewareType
+		// name records the route name used by Generate
+		name string
+		// meta records metadata attached through Route.Meta
+		meta map[string]interface{}
+		// consumes records the request media types declared through Route.Consumes
+		consumes []string
+		// produces records the response media types declared through Route.Produces
+		produces []string
+		// timeout records the handler deadline set through Route.Timeout
+		timeout time.Duration
 	}
 )
 
@@ -69,17 +79,35 @@ func (t *Tree) Add(pattern string, handle http.HandlerFunc, middleware ...Middle
 		}
 	}
 
-	if len(middleware) > 0 && currentNode.depth == 1 {
-		currentNode.middleware = append(currentNode.middleware, middleware...)
+	// 重复注册时替换整个路由, 不保留旧路由的配置
+	if currentNode.handle != nil {
+		t.clearRoute(currentNode)
 	}
-
+	currentNode.middleware = append([]MiddlewareType(nil), middleware...)
 	currentNode.handle = handle
 	currentNode.isPattern = true
 	currentNode.path = pattern
+}
+
+// node returns the node registered for `pattern` or nil, params are not expanded
+func (t *Tree) node(pattern string) *Node {
+	var currentNode = t.root
+
+	if pattern != currentNode.key {
+		pattern = trimPathPrefix(pattern)
+		for _, key := range splitPattern(pattern) {
+			node, ok := currentNode.children[key]
+			if !ok {
+				return nil
+			}
+			currentNode = node
+		}
+	}
 
-	if routeName := t.parameters.routeName; routeName != "" {
-		t.routes[routeName] = currentNode
+	if currentNode.handle == nil {
+		return nil
 	}
+	return currentNode
 }
 
 // Find returns nodes that the request match the route pattern
@@ -137,6 +165,85 @@ func (t *Tree) Find(pattern string, isRegex bool) (nodes []*Node) {
 	return
 }
 
+// Remove unregisters the handle of `pattern` and prunes the nodes left without
+// children, it reports whether a handle was registered
+func (t *Tree) Remove(pattern string) bool {
+	var (
+		currentNode = t.root
+		parents     []*Node
+	)
+
+	if pattern != currentNode.key {
+		pattern = trimPathPrefix(pattern)
+		for _, key := range splitPattern(pattern) {
+			node, ok := currentNode.children[key]
+			if !ok {
+				return false
+			}
+			parents = append(parents, currentNode)
+			currentNode = node
+		}
+	}
+
+	if currentNode.handle == nil {
+		return false
+	}
+
+	t.clearRoute(currentNode)
+
+	// 自下而上删除空节点
+	for i := len(parents) - 1; i >= 0; i-- {
+		if currentNode.isPattern || len(currentNode.children) > 0 {
+			break
+		}
+		delete(parents[i].children, currentNode.key)
+		currentNode = parents[i]
+	}
+	return true
+}
+
+// clearRoute removes the route registered on node, its name and configuration
+func (t *Tree) clearRoute(node *Node) {
+	node.handle = nil
+	node.isPattern = false
+	node.path = ""
+	node.middleware = nil
+	node.name = ""
+	node.meta = nil
+	node.consumes = nil
+	node.produces = nil
+	node.timeout = 0
+	for routeName, named := range t.routes {
+		if named == node {
+			delete(t.routes, routeName)
+		}
+	}
+}
+
+// clone returns a deep copy of the tree, used by the copy-on-write route table
+func (t *Tree) clone() *Tree {
+	nodes := make(map[*Node]*Node)
+	tree := &Tree{
+		root:   t.root.clone(nodes),
+		routes: make(map[string]*Node, len(t.routes)),
+	}
+	for routeName, node := range t.routes {
+		tree.routes[routeName] = nodes[node]
+	}
+	return tree
+}
+
+// clone returns a deep copy of the node, `nodes` maps every original node to its copy
+func (n *Node) clone(nodes map[*Node]*Node) *Node {
+	node := *n
+	node.children = make(map[string]*Node, len(n.children))
+	for key, child := range n.children {
+		node.children[key] = child.clone(nodes)
+	}
+	nodes[n] = &node
+	return &node
+}
+
 // trimPathPrefix is short for strings.TrimPrefix with param prefix `/`
 func trimPathPrefix(pattern string) string {
 	return strings.TrimPrefix(pattern, "/")
@@ -0,0 +1,161 @@
+package gorouter
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ResponseWriter wraps the http.ResponseWriter given to ServeHTTP and records
+// the status code, the number of bytes written and when the header was sent.
+// Middleware read the status and size of what their next handler writes with
+// NewResponseWriter:
+//
+//	rw := gorouter.NewResponseWriter(w)
+//	next(rw, r)
+//	log.Println(rw.Status(), rw.Size())
+type ResponseWriter struct {
+	http.ResponseWriter
+	status    int
+	size      int64
+	written   bool
+	firstByte time.Time
+	// route records the node matched by the router, nil when no route matched
+	route *Node
+	// requestID records the id set by RequestID
+	requestID string
+	// router records the router serving the request, for middleware errors
+	router *Router
+}
+
+// NewResponseWriter returns w when it is a ResponseWriter or wraps it in a new
+// one. Writers wrapping a ResponseWriter, e.g. the one of Compress, are wrapped
+// too so what next writes still goes through them.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw
+	}
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+// walkResponseWriters calls fn for w and the ResponseWriters found from w
+// through Unwrap, outermost first, until fn returns false
+func walkResponseWriters(w http.ResponseWriter, fn func(rw *ResponseWriter) bool) {
+	for inner := w; inner != nil; {
+		if rw, ok := inner.(*ResponseWriter); ok && !fn(rw) {
+			return
+		}
+		unwrapper, ok := inner.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		inner = unwrapper.Unwrap()
+	}
+}
+
+// matchedNode returns the node the router recorded on w or on a ResponseWriter
+// found through Unwrap, nil when no route matched
+func matchedNode(w http.ResponseWriter) *Node {
+	var node *Node
+	walkResponseWriters(w, func(rw *ResponseWriter) bool {
+		node = rw.route
+		return node == nil
+	})
+	return node
+}
+
+// Status returns the status code written, 0 before the header is written
+func (w *ResponseWriter) Status() int {
+	return w.status
+}
+
+// Size returns the number of body bytes written
+func (w *ResponseWriter) Size() int64 {
+	return w.size
+}
+
+// Written reports whether the header was written or the connection hijacked
+func (w *ResponseWriter) Written() bool {
+	return w.written
+}
+
+// FirstByte returns when the header was written, the zero time before
+func (w *ResponseWriter) FirstByte() time.Time {
+	return w.firstByte
+}
+
+// WriteHeader records the status code and writes the header once
+func (w *ResponseWriter) WriteHeader(status int) {
+	if w.written {
+		return
+	}
+	// 1xx 信息响应之后还会写最终的状态码
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	// 写入成功后再记录状态, 非法状态码引发的恐慌仍可返回 500
+	w.ResponseWriter.WriteHeader(status)
+	w.status = status
+	w.written = true
+	w.firstByte = time.Now()
+}
+
+// Write writes the header with 200 if needed and then the data
+func (w *ResponseWriter) Write(data []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.size += int64(n)
+	return n, err
+}
+
+// ReadFrom implements io.ReaderFrom so the wrapped writer can still use sendfile
+func (w *ResponseWriter) ReadFrom(src io.Reader) (int64, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	var (
+		n   int64
+		err error
+	)
+	if readerFrom, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = readerFrom.ReadFrom(src)
+	} else {
+		// 隐藏 ReadFrom 以免 io.Copy 递归调用
+		n, err = io.Copy(struct{ io.Writer }{w.ResponseWriter}, src)
+	}
+	w.size += n
+	return n, err
+}
+
+// FlushError flushes the wrapped writer, it returns http.ErrNotSupported when
+// the wrapped writer can't flush
+func (w *ResponseWriter) FlushError() error {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Flush implements http.Flusher
+func (w *ResponseWriter) Flush() {
+	w.FlushError()
+}
+
+// Hijack implements http.Hijacker when the wrapped writer does
+func (w *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.written = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,100 @@
+package gorouter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test middleware read the status and size through NewResponseWriter
+func TestResponseWriter_Middleware(t *testing.T) {
+	router := New()
+
+	var status int
+	var size int64
+	var firstByte bool
+	router.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			rw := NewResponseWriter(w)
+			if rw != w {
+				t.Errorf("middleware got a new ResponseWriter")
+			}
+			next(rw, r)
+			status, size, firstByte = rw.Status(), rw.Size(), !rw.FirstByte().IsZero()
+		}
+	})
+	router.GET("/created", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(expected))
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/created", nil))
+	if status != http.StatusCreated || size != int64(len(expected)) || !firstByte {
+		t.Errorf("middleware saw status %v size %v first byte %v", status, size, firstByte)
+	}
+}
+
+// Test a WriteHeader panicking on an invalid status still gets a 500
+func TestResponseWriter_InvalidStatus(t *testing.T) {
+	router := New()
+	router.PanicHandler = func(w http.ResponseWriter, r *http.Request, err interface{}) {}
+	router.GET("/invalid", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(42)
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/invalid", nil))
+	if rr.Code != http.StatusInternalServerError || rr.Body.String() != "Internal Server Error\n" {
+		t.Errorf("invalid status returned %v %q", rr.Code, rr.Body.String())
+	}
+}
+
+// Test ReadFrom, Flush, Hijack and Unwrap of ResponseWriter
+func TestResponseWriter_Interfaces(t *testing.T) {
+	rr := httptest.NewRecorder()
+	rw := NewResponseWriter(rr)
+	if rw.Status() != 0 || rw.Written() {
+		t.Errorf("new ResponseWriter is written")
+	}
+
+	n, err := rw.ReadFrom(strings.NewReader(expected))
+	if err != nil || n != int64(len(expected)) || rw.Size() != n || rw.Status() != http.StatusOK {
+		t.Errorf("ReadFrom returned %v %v, size %v status %v", n, err, rw.Size(), rw.Status())
+	}
+	if rr.Body.String() != expected {
+		t.Errorf(errorFormat, rr.Body.String(), expected)
+	}
+
+	// http.ResponseController 通过 Unwrap 找到底层的 writer
+	if err := http.NewResponseController(rw).Flush(); err != nil || !rr.Flushed {
+		t.Errorf("Flush returned %v, flushed %v", err, rr.Flushed)
+	}
+	if _, _, err := rw.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Hijack returned %v want %v", err, http.ErrNotSupported)
+	}
+	if rw.Unwrap() != rr {
+		t.Errorf("Unwrap didn't return the wrapped writer")
+	}
+
+	// 外层 writer 会被再次包装, 以免跳过它
+	wrapper := &unwrapWriter{rw}
+	if NewResponseWriter(rw) != rw || NewResponseWriter(wrapper).Unwrap() != wrapper {
+		t.Errorf("NewResponseWriter skipped the wrapping writer")
+	}
+	rw.route = &Node{path: "users"}
+	if matchedNode(NewResponseWriter(wrapper)) != rw.route {
+		t.Errorf("matchedNode didn't find the route through Unwrap")
+	}
+}
+
+// unwrapWriter wraps a writer and exposes it through Unwrap
+type unwrapWriter struct {
+	http.ResponseWriter
+}
+
+func (w *unwrapWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}